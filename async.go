@@ -0,0 +1,56 @@
+package hfs
+
+import "context"
+
+// AsyncResult is the pending result of DoAsync(). Wait() and Poll() may be called
+// any number of times and from several goroutines.
+type AsyncResult[O any] struct {
+	EventID string
+
+	done chan struct{}
+	res  []O
+	err  error
+}
+
+// DoAsync performs the POST right away and returns once the space accepted the request.
+// The event stream is read in the background until the result is in or ctx is done.
+// A failed POST is returned as is, or, with WithErrorHandler, as an AsyncResult already
+// holding the handler's outcome and no EventID.
+func (h *HFSpace[I, O]) DoAsync(ctx context.Context, endpoint string, params ...I) (*AsyncResult[O], error) {
+	eventID, err := h.Submit(ctx, endpoint, params...)
+	if err != nil {
+		if h.errorHandler == nil {
+			return nil, err
+		}
+		ar := &AsyncResult[O]{done: make(chan struct{})}
+		ar.res, ar.err = h.errorHandler(err)
+		close(ar.done)
+		return ar, nil
+	}
+
+	ar := &AsyncResult[O]{EventID: eventID, done: make(chan struct{})}
+	go func() {
+		defer close(ar.done)
+		ar.res, ar.err = h.FetchResult(ctx, endpoint, eventID)
+		if ar.err != nil && h.errorHandler != nil {
+			ar.res, ar.err = h.errorHandler(ar.err)
+		}
+	}()
+	return ar, nil
+}
+
+// Wait blocks until the result is in.
+func (ar *AsyncResult[O]) Wait() ([]O, error) {
+	<-ar.done
+	return ar.res, ar.err
+}
+
+// Poll returns the result and true if it is in, or false without blocking.
+func (ar *AsyncResult[O]) Poll() ([]O, bool, error) {
+	select {
+	case <-ar.done:
+		return ar.res, true, ar.err
+	default:
+		return nil, false, nil
+	}
+}
@@ -0,0 +1,66 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_DoAsync(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt-1"}`))
+			return
+		}
+		<-release
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	ar, err := newTestHfs[any, string](srv).DoAsync(context.Background(), "/predict", "x")
+	if err != nil {
+		t.Fatalf("DoAsync returned error: %v", err)
+	}
+	if ar.EventID != "evt-1" {
+		t.Fatalf("expected event ID evt-1, got %q", ar.EventID)
+	}
+	if _, done, _ := ar.Poll(); done {
+		t.Fatalf("expected Poll to report a pending result")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if res, err := ar.Wait(); err != nil || len(res) != 1 || res[0] != "ok" {
+			t.Errorf("Wait returned %v, %v", res, err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			res, done, err := ar.Poll()
+			if !done {
+				time.Sleep(time.Millisecond)
+				continue
+			}
+			if err != nil || len(res) != 1 || res[0] != "ok" {
+				t.Errorf("Poll returned %v, %v", res, err)
+			}
+			return
+		}
+	}()
+	close(release)
+	wg.Wait()
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	if _, err := newTestHfs[any, string](missing).DoAsync(context.Background(), "/predict"); !errors.Is(err, ErrHTTPStatus) {
+		t.Fatalf("expected the POST error, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package hfs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BatchResult is the outcome of one input of DoBatch().
+type BatchResult[O any] struct {
+	Index int // position of the input in the inputs slice
+	Data  []O
+	Err   error
+}
+
+// DoBatch runs DoWithContext() for every element of inputs, at most concurrency at a time.
+// Results are in input order; a failed input only sets Err on its own result.
+// Cancelling ctx aborts requests in flight and fails the ones not started yet,
+// in which case ctx's error is also returned.
+func (h *HFSpace[I, O]) DoBatch(ctx context.Context, endpoint string, concurrency int, inputs [][]I) ([]BatchResult[O], error) {
+	if concurrency < 1 {
+		return nil, fmt.Errorf("hfs batch concurrency must be at least 1, got %d", concurrency)
+	}
+
+	results := make([]BatchResult[O], len(inputs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, params := range inputs {
+		results[i].Index = i
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Data, results[i].Err = h.DoWithContext(ctx, endpoint, params...)
+		}()
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
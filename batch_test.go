@@ -0,0 +1,77 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_DoBatch(t *testing.T) {
+	var active, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/fail/evt") {
+			w.Write([]byte("event: error\ndata: null\n\n"))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[int, string](srv)
+
+	inputs := make([][]int, 10)
+	for i := range inputs {
+		inputs[i] = []int{i}
+	}
+	start := time.Now()
+	results, err := hfs.DoBatch(context.Background(), "/predict", 3, inputs)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("DoBatch returned error: %v", err)
+	}
+	if peak.Load() != 3 {
+		t.Fatalf("expected 3 concurrent requests, got %d", peak.Load())
+	}
+	if elapsed < 400*time.Millisecond || elapsed > 800*time.Millisecond {
+		t.Fatalf("expected about 4 rounds of 100ms, took %v", elapsed)
+	}
+	for i, res := range results {
+		if res.Index != i || res.Err != nil || len(res.Data) != 1 || res.Data[0] != "ok" {
+			t.Fatalf("unexpected result %d: %+v", i, res)
+		}
+	}
+
+	results, err = hfs.DoBatch(context.Background(), "/fail", 2, inputs[:2])
+	if err != nil || !errors.Is(results[1].Err, ErrEventError) {
+		t.Fatalf("expected per-input event error, got %v / %+v", err, results)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	results, err = hfs.DoBatch(ctx, "/predict", 1, inputs[:3])
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	for _, res := range results {
+		if res.Err == nil {
+			t.Fatalf("expected every result to fail after cancellation, got %+v", res)
+		}
+	}
+}
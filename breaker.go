@@ -0,0 +1,56 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops requests to a space after threshold consecutive failures.
+// Once resetAfter has passed since the last failure it lets a single probe through:
+// success closes the circuit, failure keeps it open for another resetAfter.
+type circuitBreaker struct {
+	threshold  int
+	resetAfter time.Duration
+
+	mu          sync.Mutex
+	failures    int
+	lastFailure time.Time
+	probing     bool
+}
+
+func newCircuitBreaker(threshold int, resetAfter time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, resetAfter: resetAfter}
+}
+
+// allow reports whether a request may be sent. A true result must be followed by record().
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.failures < cb.threshold {
+		return true
+	}
+	if cb.probing || time.Since(cb.lastFailure) < cb.resetAfter {
+		return false
+	}
+	cb.probing = true
+	return true
+}
+
+// record counts the outcome of an allowed request.
+// Cancellations by the caller say nothing about the space and are not counted.
+func (cb *circuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+	switch {
+	case err == nil:
+		cb.failures = 0
+	case errors.Is(err, context.Canceled):
+		// Neither success nor failure; a cancelled probe lets the next request probe again.
+	default:
+		cb.failures++
+		cb.lastFailure = time.Now()
+	}
+}
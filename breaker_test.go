@@ -0,0 +1,84 @@
+package hfs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_CircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts.Add(1)
+			if failing.Load() {
+				http.Error(w, "sleeping", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, string](srv).WithCircuitBreaker(2, 100*time.Millisecond)
+
+	failing.Store(true)
+	for range 2 {
+		if _, err := hfs.Do(test_endpoint); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected the space's error, got %v", err)
+		}
+	}
+	if _, err := hfs.Do(test_endpoint); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after threshold, got %v", err)
+	}
+	if posts.Load() != 2 {
+		t.Fatalf("expected no request while open, got %d POSTs", posts.Load())
+	}
+
+	// open -> half-open -> open
+	time.Sleep(120 * time.Millisecond)
+	if _, err := hfs.Do(test_endpoint); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the probe to reach the space and fail, got %v", err)
+	}
+	if _, err := hfs.Do(test_endpoint); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after failed probe, got %v", err)
+	}
+	if posts.Load() != 3 {
+		t.Fatalf("expected exactly one probe, got %d POSTs", posts.Load())
+	}
+
+	// open -> half-open -> closed
+	failing.Store(false)
+	time.Sleep(120 * time.Millisecond)
+	if _, err := hfs.Do(test_endpoint); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if _, err := hfs.Do(test_endpoint); err != nil {
+		t.Fatalf("expected a closed circuit after successful probe, got %v", err)
+	}
+	if posts.Load() != 5 {
+		t.Fatalf("expected 5 POSTs, got %d", posts.Load())
+	}
+}
+
+func Test_CircuitBreakerSingleProbe(t *testing.T) {
+	cb := newCircuitBreaker(1, 0)
+	cb.allow()
+	cb.record(errors.New("down"))
+
+	if !cb.allow() {
+		t.Fatalf("expected the first caller after resetAfter to probe")
+	}
+	if cb.allow() {
+		t.Fatalf("expected other callers to be rejected while the probe is in flight")
+	}
+	cb.record(nil)
+	if !cb.allow() {
+		t.Fatalf("expected a closed circuit after successful probe")
+	}
+}
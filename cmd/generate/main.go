@@ -0,0 +1,244 @@
+// Command generate writes a typed Go client for a Hugging Face Space
+// from the endpoint description served at /gradio_api/info.
+//
+// Usage:
+//
+//	go run github.com/ucukertz/hfs/cmd/generate -space <name> [-token <hf token>] [-pkg client] [-out client.go]
+//
+// Each named endpoint becomes a method with Go-typed parameters and results,
+// e.g. "/predict" taking (Textbox, Slider) and returning Image generates
+//
+//	Predict(ctx context.Context, text string, slider float64) (*hfs.FileData, error)
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/ucukertz/hfs"
+)
+
+func main() {
+	space := flag.String("space", "", "Hugging Face Space name, e.g. owner-app (required)")
+	hfToken := flag.String("token", os.Getenv("HF_TOKEN"), "HF token, defaults to $HF_TOKEN")
+	pkg := flag.String("pkg", "client", "package name of the generated file")
+	typeName := flag.String("type", "Client", "name of the generated client type")
+	out := flag.String("out", "", "output file, defaults to stdout")
+	flag.Parse()
+
+	if *space == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	info, err := fetchInfo(*space, *hfToken)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	src, err := generate(info, *space, *pkg, *typeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func fetchInfo(space, hfToken string) (*hfs.SpaceInfo, error) {
+	h := hfs.NewHfs[any, any](space, hfs.WithTimeout(60*time.Second))
+	if hfToken != "" {
+		h.WithBearerToken(hfToken)
+	}
+	info, err := h.Info()
+	if err != nil {
+		return nil, fmt.Errorf("generate info: %w", err)
+	}
+	return info, nil
+}
+
+// goTypes maps Gradio components to Go types. Anything else becomes `any`.
+var goTypes = map[string]string{
+	"Textbox":       "string",
+	"TextArea":      "string",
+	"Dropdown":      "string",
+	"Radio":         "string",
+	"Markdown":      "string",
+	"HTML":          "string",
+	"Code":          "string",
+	"ColorPicker":   "string",
+	"Number":        "float64",
+	"Slider":        "float64",
+	"Checkbox":      "bool",
+	"CheckboxGroup": "[]string",
+	"Image":         "*hfs.FileData",
+	"ImageEditor":   "*hfs.FileData",
+	"Audio":         "*hfs.FileData",
+	"Video":         "*hfs.FileData",
+	"File":          "*hfs.FileData",
+	"Model3D":       "*hfs.FileData",
+	"JSON":          "any",
+}
+
+func goType(component string) string {
+	if t, ok := goTypes[component]; ok {
+		return t
+	}
+	return "any"
+}
+
+type method struct {
+	Name     string
+	Endpoint string
+	Params   []field
+	Returns  []field
+}
+
+type field struct {
+	Name string
+	Type string
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by github.com/ucukertz/hfs/cmd/generate. DO NOT EDIT.
+
+package {{.Pkg}}
+
+import (
+	"context"
+	"encoding/json"
+{{- if .HasReturns}}
+	"fmt"
+{{- end}}
+
+	"github.com/ucukertz/hfs"
+)
+
+// {{.Type}} is a typed client for the {{.Space}} Hugging Face Space.
+type {{.Type}} struct {
+	Space *hfs.HFSpace[any, json.RawMessage]
+}
+
+// New{{.Type}} creates a {{.Type}}. token may be empty for public spaces.
+func New{{.Type}}(token string) *{{.Type}} {
+	space := hfs.NewHfs[any, json.RawMessage]({{printf "%q" .Space}})
+	if token != "" {
+		space.WithBearerToken(token)
+	}
+	return &{{.Type}}{Space: space}
+}
+{{range .Methods}}{{$m := .}}
+// {{.Name}} calls {{.Endpoint}}.
+func (c *{{$.Type}}) {{.Name}}(ctx context.Context{{range .Params}}, {{.Name}} {{.Type}}{{end}}) ({{range .Returns}}{{.Name}} {{.Type}}, {{end}}err error) {
+{{- if not .Returns}}
+	_, err = c.Space.DoWithContext(ctx, {{printf "%q" .Endpoint}}{{range .Params}}, {{.Name}}{{end}})
+	return
+}
+{{else}}
+	res, err := c.Space.DoWithContext(ctx, {{printf "%q" .Endpoint}}{{range .Params}}, {{.Name}}{{end}})
+	if err != nil {
+		return
+	}
+	if len(res) < {{len .Returns}} {
+		err = fmt.Errorf("{{.Endpoint}}: expected {{len .Returns}} outputs, got %d: %w", len(res), hfs.ErrNoData)
+		return
+	}
+{{- range $i, $r := .Returns}}
+	if err = json.Unmarshal(res[{{$i}}], &{{$r.Name}}); err != nil {
+		err = fmt.Errorf("{{$m.Endpoint}}: output {{$i}}: %w", err)
+		return
+	}
+{{- end}}
+	return
+}
+{{end}}{{end}}`))
+
+// generate renders the client source for every named endpoint in info.
+func generate(info *hfs.SpaceInfo, space, pkg, typeName string) ([]byte, error) {
+	endpoints := make([]string, 0, len(info.NamedEndpoints))
+	for name := range info.NamedEndpoints {
+		endpoints = append(endpoints, name)
+	}
+	sort.Strings(endpoints)
+
+	var methods []method
+	hasReturns := false
+	for _, endpoint := range endpoints {
+		ep := info.NamedEndpoints[endpoint]
+		m := method{Name: exportedName(endpoint), Endpoint: endpoint}
+		used := map[string]bool{"c": true, "ctx": true, "res": true, "err": true}
+		for i, p := range ep.Parameters {
+			m.Params = append(m.Params, field{Name: uniqueName(p.ParameterName, fmt.Sprintf("param%d", i), used), Type: goType(p.Component)})
+		}
+		for i, r := range ep.Returns {
+			m.Returns = append(m.Returns, field{Name: uniqueName("", fmt.Sprintf("out%d", i), used), Type: goType(r.Component)})
+		}
+		methods = append(methods, m)
+		hasReturns = hasReturns || len(m.Returns) > 0
+	}
+
+	var buf bytes.Buffer
+	err := clientTemplate.Execute(&buf, map[string]any{
+		"Pkg":        pkg,
+		"Type":       typeName,
+		"Space":      space,
+		"Methods":    methods,
+		"HasReturns": hasReturns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("generate template: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generate format: %w", err)
+	}
+	return src, nil
+}
+
+// exportedName turns an endpoint like "/generate_image" into "GenerateImage".
+func exportedName(endpoint string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(endpoint, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "Call" + name
+	}
+	return name
+}
+
+// uniqueName turns a Python parameter name into an unused Go identifier, or fallback if it has none.
+func uniqueName(pyName, fallback string, used map[string]bool) string {
+	name := exportedName(pyName)
+	if pyName == "" || name == "Call" {
+		name = fallback
+	} else {
+		name = strings.ToLower(name[:1]) + name[1:]
+	}
+	if token.IsKeyword(name) || used[name] {
+		name = fallback
+	}
+	for used[name] {
+		name += "_"
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ucukertz/hfs"
+)
+
+var test_info = `{
+	"named_endpoints": {
+		"/predict": {
+			"parameters": [
+				{"label": "Text", "parameter_name": "text", "component": "Textbox"},
+				{"label": "Strength", "parameter_name": "slider", "component": "Slider"}
+			],
+			"returns": [{"label": "Result", "component": "Image"}]
+		},
+		"/lambda_1": {
+			"parameters": [{"label": "Type", "parameter_name": "type", "component": "Checkbox"}],
+			"returns": []
+		}
+	},
+	"unnamed_endpoints": {}
+}`
+
+func Test_Generate(t *testing.T) {
+	var info hfs.SpaceInfo
+	if err := json.Unmarshal([]byte(test_info), &info); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+
+	src, err := generate(&info, "owner-app", "client", "Client")
+	if err != nil {
+		t.Fatalf("generate() returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"package client",
+		`hfs.NewHfs[any, json.RawMessage]("owner-app")`,
+		"func (c *Client) Predict(ctx context.Context, text string, slider float64) (out0 *hfs.FileData, err error)",
+		`c.Space.DoWithContext(ctx, "/predict", text, slider)`,
+		"func (c *Client) Lambda1(ctx context.Context, param0 bool) (err error)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Fatalf("generated source missing %q:\n%s", want, src)
+		}
+	}
+}
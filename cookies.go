@@ -0,0 +1,54 @@
+package hfs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// WithCookieJar stores the cookies the space sets, including on redirects, in jar and sends
+// them back on later requests, for spaces with a login flow setting a session cookie.
+// A nil jar is replaced by a new one using the public suffix list of golang.org/x/net/publicsuffix.
+// The jar is installed on a copy of the client, so a client shared through WithHTTPClient is not modified.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *config) {
+		if jar == nil {
+			jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List}) // never fails
+		}
+		client := *c.client
+		client.Jar = jar
+		c.client = &client
+	}
+}
+
+// WithDefaultCookieJar is WithCookieJar(nil): it keeps cookies in a new jar using the public suffix list.
+func WithDefaultCookieJar() Option {
+	return WithCookieJar(nil)
+}
+
+// WithSessionCookie sends the cookie name=value to the space, for private spaces authenticated
+// by the session cookie of a browser login. The cookie is stored in the cookie jar of the client,
+// created with cookiejar.New(nil) if it has none, for the host of BaseURL at the time the option
+// is applied, so set WithBaseURL() first. Every call adds another cookie.
+// A jar the client already had is shared with the spaces and clients using it.
+func WithSessionCookie(name, value string) Option {
+	return func(c *config) {
+		space, err := url.Parse(c.BaseURL)
+		if err != nil {
+			if c.optErr == nil {
+				c.optErr = fmt.Errorf("hfs session cookie: %w", err)
+			}
+			return
+		}
+		client := *c.client
+		if client.Jar == nil {
+			client.Jar, _ = cookiejar.New(nil) // never fails without options
+		}
+		client.Jar.SetCookies(&url.URL{Scheme: space.Scheme, Host: space.Host, Path: "/"},
+			[]*http.Cookie{{Name: name, Value: value, Path: "/"}})
+		c.client = &client
+	}
+}
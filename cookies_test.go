@@ -0,0 +1,77 @@
+package hfs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_WithSessionCookie(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err1 := r.Cookie("session")
+		csrf, err2 := r.Cookie("csrf")
+		if err1 != nil || err2 != nil || session.Value != "s3cr3t" || csrf.Value != "tok" {
+			http.Error(w, "login required", http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	var herr *HTTPStatusError
+	if _, err := newTestHfs[any, string](srv).Do("/predict", "x"); !errors.As(err, &herr) || herr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without cookies, got %v", err)
+	}
+
+	hfs := newTestHfs[any, string](srv)
+	client := hfs.client
+	hfs.WithSessionCookie("session", "s3cr3t").WithSessionCookie("csrf", "tok")
+	res, err := hfs.Do("/predict", "x")
+	if err != nil || len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("Do() returned %v, %v", res, err)
+	}
+	if client.Jar != nil {
+		t.Fatalf("expected the original client to be left unchanged")
+	}
+}
+
+func Test_WithCookieJar(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/"})
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		if session, err := r.Cookie("session"); err != nil || session.Value != "s3cr3t" {
+			http.Error(w, "login required", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	var herr *HTTPStatusError
+	if _, err := newTestHfs[any, string](srv).Do("/predict", "x"); !errors.As(err, &herr) || herr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the cookie to be dropped without a jar, got %v", err)
+	}
+
+	hfs := newTestHfs[any, string](srv).WithDefaultCookieJar()
+	if hfs.client.Jar == nil {
+		t.Fatalf("expected a new cookie jar")
+	}
+	res, err := hfs.Do("/predict", "x")
+	if err != nil || len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("expected the cookie of the POST to be sent with the GET, got %v, %v", res, err)
+	}
+
+	jar, _ := cookiejar.New(nil)
+	if hfs.WithCookieJar(jar); hfs.client.Jar != jar {
+		t.Fatalf("expected the given jar to be installed")
+	}
+}
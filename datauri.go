@@ -0,0 +1,93 @@
+package hfs
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// mimeExtensions maps the mime types of common Gradio inputs to a file extension.
+// Others fall back to mime.ExtensionsByType, then to "bin".
+var mimeExtensions = map[string]string{
+	"image/png":                "png",
+	"image/jpeg":               "jpg",
+	"image/gif":                "gif",
+	"image/webp":               "webp",
+	"image/svg+xml":            "svg",
+	"audio/mpeg":               "mp3",
+	"audio/wav":                "wav",
+	"audio/x-wav":              "wav",
+	"audio/ogg":                "ogg",
+	"audio/flac":               "flac",
+	"video/mp4":                "mp4",
+	"video/webm":               "webm",
+	"application/pdf":          "pdf",
+	"application/json":         "json",
+	"application/octet-stream": "bin",
+	"text/plain":               "txt",
+	"text/csv":                 "csv",
+}
+
+// extensionOf returns the file extension for mimeType, without the dot.
+func extensionOf(mimeType string) string {
+	if ext, ok := mimeExtensions[mimeType]; ok {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+		return strings.TrimPrefix(exts[0], ".")
+	}
+	return "bin"
+}
+
+// FromDataURI uploads the content of a data URI such as "data:image/png;base64,iVBOR...",
+// like FromBytes(). MimeType is set from the URI, and the file is named "file.<ext>"
+// after it unless fd already has a name. Both base64 and percent-encoded data are accepted.
+func (fd *FileData) FromDataURI(dataURI string) (*FileData, error) {
+	mimeType, data, err := parseDataURI(dataURI)
+	if err != nil {
+		return nil, withKind(ErrDecodeFailure, err)
+	}
+	fd.MimeType = &mimeType
+	if fd.OrigName == "" {
+		fd.OrigName = "file." + extensionOf(mimeType)
+	}
+	return fd.FromBytes(data)
+}
+
+// parseDataURI splits an RFC 2397 data URI into its mime type and decoded content.
+func parseDataURI(dataURI string) (string, []byte, error) {
+	rest, ok := strings.CutPrefix(dataURI, "data:")
+	if !ok {
+		return "", nil, errors.New("hfs data uri must start with data:")
+	}
+	header, payload, ok := strings.Cut(rest, ",")
+	if !ok {
+		return "", nil, errors.New("hfs data uri has no comma before the data")
+	}
+
+	header, isBase64 := strings.CutSuffix(header, ";base64")
+	mimeType := "text/plain" // the default of RFC 2397
+	if header != "" && !strings.HasPrefix(header, ";") {
+		mediaType, _, err := mime.ParseMediaType(header)
+		if err != nil || !strings.Contains(mediaType, "/") {
+			return "", nil, fmt.Errorf("hfs data uri mime type %q is invalid", header)
+		}
+		mimeType = mediaType
+	}
+
+	if isBase64 {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return "", nil, fmt.Errorf("hfs data uri base64 decode: %w", err)
+		}
+		return mimeType, data, nil
+	}
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("hfs data uri unescape: %w", err)
+	}
+	return mimeType, []byte(data), nil
+}
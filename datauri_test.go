@@ -0,0 +1,44 @@
+package hfs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func Test_FromDataURI(t *testing.T) {
+	png := readFixture(t, "pixel.png")
+	for _, tc := range []struct {
+		uri, mime, name string
+		content         []byte
+	}{
+		{"data:image/png;base64," + base64.StdEncoding.EncodeToString(png), "image/png", "file.png", png},
+		{"data:audio/mpeg;base64,SUQz", "audio/mpeg", "file.mp3", []byte("ID3")},
+		{"data:application/octet-stream;base64,AAEC", "application/octet-stream", "file.bin", []byte{0, 1, 2}},
+		{"data:,hello%20world", "text/plain", "file.txt", []byte("hello world")},
+	} {
+		u := newMemoryUploader(t)
+		fd, err := NewFileData("").WithUploader(u).FromDataURI(tc.uri)
+		if err != nil {
+			t.Fatalf("FromDataURI(%.30q) returned error: %v", tc.uri, err)
+		}
+		if fd.MimeType == nil || *fd.MimeType != tc.mime || fd.OrigName != tc.name {
+			t.Fatalf("expected %s named %s, got %v named %s", tc.mime, tc.name, fd.MimeType, fd.OrigName)
+		}
+		if got := u.files["/"+tc.name]; !bytes.Equal(got, tc.content) {
+			t.Fatalf("expected %q to be uploaded, got %q", tc.content, got)
+		}
+	}
+
+	fd, err := NewFileData("cat.png").WithUploader(newMemoryUploader(t)).FromDataURI("data:image/png;base64,AAEC")
+	if err != nil || fd.OrigName != "cat.png" {
+		t.Fatalf("expected the given name to be kept, got %v, %v", fd, err)
+	}
+
+	for _, uri := range []string{"image/png;base64,AAEC", "data:image/png;base64", "data:image/png;base64,%%%", "data:nonsense;base64,AAEC"} {
+		if _, err := NewFileData("").WithUploader(newMemoryUploader(t)).FromDataURI(uri); !errors.Is(err, ErrDecodeFailure) {
+			t.Errorf("expected ErrDecodeFailure for %q, got %v", uri, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package hfs
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// WithDeduplication is WithDeduplicationWindow(ttl): identical calls, with the same endpoint and
+// marshaled params, share the in-flight or recently completed result of the first one for ttl.
+func WithDeduplication(ttl time.Duration) Option {
+	return WithDeduplicationWindow(ttl)
+}
+
+// WithDeduplicationMaxSize bounds the completed results kept for deduplication to n,
+// evicting the least recently used ones first. Requests still in flight are not counted.
+// 0, the default, keeps every result until its window is over.
+func WithDeduplicationMaxSize(n int) Option {
+	return func(c *config) {
+		c.dedupMaxSize = n
+	}
+}
+
+// dedupLRU orders the completed entries of HFSpace.dedup by last use. The zero value is ready to use.
+type dedupLRU struct {
+	mu    sync.Mutex
+	order *list.List // of *dedupEntry, most recently used first
+}
+
+// add records entry as the most recently used and returns the entries over max to evict, if max > 0.
+func (l *dedupLRU) add(entry *dedupEntry, max int) []*dedupEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.order == nil {
+		l.order = list.New()
+	}
+	entry.elem = l.order.PushFront(entry)
+	var evicted []*dedupEntry
+	for max > 0 && l.order.Len() > max {
+		old := l.order.Remove(l.order.Back()).(*dedupEntry)
+		old.elem = nil
+		evicted = append(evicted, old)
+	}
+	return evicted
+}
+
+// touch marks entry as the most recently used.
+func (l *dedupLRU) touch(entry *dedupEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry.elem != nil {
+		l.order.MoveToFront(entry.elem)
+	}
+}
+
+// remove forgets entry, e.g. once its window is over.
+func (l *dedupLRU) remove(entry *dedupEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry.elem != nil {
+		l.order.Remove(entry.elem)
+		entry.elem = nil
+	}
+}
@@ -0,0 +1,106 @@
+package hfs
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// redactedHeaders are replaced by "[REDACTED]" in debug dumps unless WithDebugDumpIncludeAuth(true) is set.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// WithDebugDump writes every request and response going to the space to w as they are
+// on the wire, headers and body, for tracing API issues. Response bodies are copied to w
+// as they are read, so event streams are dumped live. Authorization and cookie headers
+// are redacted unless WithDebugDumpIncludeAuth(true) is set. A nil w turns dumping off.
+func WithDebugDump(w io.Writer) Option {
+	return func(c *config) {
+		c.dump = nil
+		if w != nil {
+			c.dump = &syncWriter{w: w}
+		}
+	}
+}
+
+// WithDebugDumpIncludeAuth keeps credentials readable in the output of WithDebugDump.
+func WithDebugDumpIncludeAuth(include bool) Option {
+	return func(c *config) {
+		c.dumpAuth = include
+	}
+}
+
+// syncWriter serializes writes to a debug dump shared by concurrent requests.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (sw *syncWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.w.Write(p)
+}
+
+// dumpClient returns a copy of the client whose transport dumps to c.dump.
+func (c *config) dumpClient() *http.Client {
+	client := *c.client
+	inner := client.Transport
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	client.Transport = &dumpTransport{inner: inner, w: c.dump, includeAuth: c.dumpAuth}
+	return &client
+}
+
+// dumpTransport writes the requests and responses going through inner to w.
+type dumpTransport struct {
+	inner       http.RoundTripper
+	w           io.Writer
+	includeAuth bool
+}
+
+func (dt *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	withBody := false
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			out.Body, withBody = body, true
+		}
+	}
+	dt.redact(out.Header)
+	if b, err := httputil.DumpRequestOut(out, withBody); err == nil {
+		dt.w.Write(append(b, '\n'))
+	} else {
+		fmt.Fprintf(dt.w, "hfs dump request: %v\n", err)
+	}
+
+	resp, err := dt.inner.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(dt.w, "hfs dump response: %v\n\n", err)
+		return nil, err
+	}
+	head := *resp
+	head.Header = resp.Header.Clone()
+	dt.redact(head.Header)
+	if b, err := httputil.DumpResponse(&head, false); err == nil {
+		dt.w.Write(b)
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(resp.Body, dt.w), resp.Body}
+	return resp, nil
+}
+
+func (dt *dumpTransport) redact(h http.Header) {
+	if dt.includeAuth {
+		return
+	}
+	for _, key := range redactedHeaders {
+		if h.Get(key) != "" {
+			h.Set(key, "[REDACTED]")
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package hfs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_WithDebugDump(t *testing.T) {
+	srv := fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", nil)
+	var buf bytes.Buffer
+	hfs := newTestHfs[any, string](srv).WithBearerToken("secret").WithDebugDump(&buf)
+
+	res, err := hfs.Do("/predict", "cat")
+	if err != nil || len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("Do returned %v, %v", res, err)
+	}
+	for _, want := range []string{
+		"POST /gradio_api/call/predict HTTP/1.1\r\n",
+		"Authorization: [REDACTED]\r\n",
+		`{"data":["cat"]}`,
+		"HTTP/1.1 200 OK\r\n",
+		`{"event_id":"evt"}`,
+		"GET /gradio_api/call/predict/evt HTTP/1.1\r\n",
+		"event: complete\ndata: [\"ok\"]\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected dump to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatalf("expected the token to be redacted, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := hfs.WithDebugDumpIncludeAuth(true).Do("/predict", "cat"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Authorization: Bearer secret\r\n") {
+		t.Fatalf("expected the token with WithDebugDumpIncludeAuth(true), got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := hfs.WithDebugDump(nil).Do("/predict", "cat"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected WithDebugDump(nil) to turn dumping off, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,110 @@
+package hfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Endpoint bases of the Gradio API versions, see WithEndpointBase.
+const (
+	EndpointBaseGradio5 = "/gradio_api/call" // Gradio 5 and later, the default
+	EndpointBaseGradio4 = "/call"            // Gradio 4
+	EndpointBaseGradio3 = "/run"             // Gradio 3, synchronous
+	EndpointBaseGradio2 = "/api"             // Gradio 2 and 3, synchronous
+)
+
+// WithEndpointBase sets the path under which the endpoints of the space are served, replacing
+// the one at the end of BaseURL: EndpointBaseGradio5 ("/gradio_api/call") by default, or e.g.
+// "/call" for Gradio 4. The synchronous APIs of older versions, "/run" and "/api", answer the
+// POST with the result itself; Do() and friends handle that, and DoStream() delivers it as the
+// only event, while Submit() and FetchResult() need the event streams of the "call" API.
+func WithEndpointBase(base string) Option {
+	return func(c *config) {
+		base = "/" + strings.Trim(base, "/")
+		c.BaseURL = strings.TrimSuffix(c.BaseURL, c.endpointBase) + base
+		c.endpointBase = base
+	}
+}
+
+// NewHFSpaceForGradioVersion is NewHfs() for a space running the given Gradio version, e.g. "4.44.1",
+// using the endpoint base that version serves its API under.
+func NewHFSpaceForGradioVersion[I, O any](name, version string, opts ...Option) (*HFSpace[I, O], error) {
+	base, err := endpointBaseFor(version)
+	if err != nil {
+		return nil, err
+	}
+	return NewHfs[I, O](name, append([]Option{WithEndpointBase(base)}, opts...)...), nil
+}
+
+// endpointBaseFor maps a Gradio version to its endpoint base by major version.
+func endpointBaseFor(version string) (string, error) {
+	major, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	n, err := strconv.Atoi(major)
+	if err != nil || n < 1 {
+		return "", fmt.Errorf("hfs gradio version %q is not a version number", version)
+	}
+	switch {
+	case n >= 5:
+		return EndpointBaseGradio5, nil
+	case n == 4:
+		return EndpointBaseGradio4, nil
+	case n == 3:
+		return EndpointBaseGradio3, nil
+	default:
+		return EndpointBaseGradio2, nil
+	}
+}
+
+// syncAPI reports whether the endpoint base is a synchronous API, answering the POST with the result.
+func (c *config) syncAPI() bool {
+	return c.endpointBase == EndpointBaseGradio3 || c.endpointBase == EndpointBaseGradio2
+}
+
+// postSync sends the request body to a synchronous API and returns the "data" of its reply,
+// like fetch() returns the data of the complete event.
+func (h *HFSpace[I, O]) postSync(ctx context.Context, fullURL string, body []byte) (_ []byte, err error) {
+	status := 0
+	if h.limiter != nil {
+		if err := h.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("hfs rate limit wait: %w", err)
+		}
+	}
+	ctx, p := h.startPhase(ctx, "post", fullURL, h.endpointOf(fullURL))
+	defer func() { p.end(status, "", err) }()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("hfs post req create: %w", err)
+	}
+	h.applyHeaders(req.Header)
+
+	resp, err := h.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("hfs post req exec: %w", err)
+	}
+	defer resp.Body.Close()
+	status = resp.StatusCode
+	if err := checkStatus(resp); err != nil {
+		return nil, fmt.Errorf("hfs post resp: %w", err)
+	}
+
+	var result struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(h.limitBody(resp.Body)).Decode(&result); err != nil {
+		if errors.Is(err, ErrResponseTooLarge) {
+			return nil, fmt.Errorf("hfs post resp read: %w", err)
+		}
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("hfs result decode: %w", err))
+	}
+	if len(result.Data) == 0 {
+		return nil, ErrNoData
+	}
+	return result.Data, nil
+}
@@ -0,0 +1,68 @@
+package hfs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_WithEndpointBase(t *testing.T) {
+	for _, tc := range []struct {
+		base, endpoint, info string
+	}{
+		{"/gradio_api/call", "https://test.hf.space/gradio_api/call/predict", "https://test.hf.space/gradio_api/info"},
+		{"call/", "https://test.hf.space/call/predict", "https://test.hf.space/info"},
+		{"/run", "https://test.hf.space/run/predict", "https://test.hf.space/info"},
+		{"/api", "https://test.hf.space/api/predict", "https://test.hf.space/info"},
+	} {
+		h := NewHfs[string, string]("test").WithEndpointBase("/run").WithEndpointBase(tc.base)
+		if got := h.endpointURL("/predict"); got != tc.endpoint {
+			t.Errorf("%s: expected endpoint URL %s, got %s", tc.base, tc.endpoint, got)
+		}
+		if got := h.infoURL(); got != tc.info {
+			t.Errorf("%s: expected info URL %s, got %s", tc.base, tc.info, got)
+		}
+		if got := h.rootURL(); got != "https://test.hf.space/" {
+			t.Errorf("%s: expected root URL https://test.hf.space/, got %s", tc.base, got)
+		}
+	}
+}
+
+func Test_NewHFSpaceForGradioVersion(t *testing.T) {
+	for version, want := range map[string]string{
+		"5.9.1":  "https://test.hf.space/gradio_api/call/predict",
+		"v4.44":  "https://test.hf.space/call/predict",
+		"3.50.2": "https://test.hf.space/run/predict",
+		"2":      "https://test.hf.space/api/predict",
+	} {
+		h, err := NewHFSpaceForGradioVersion[string, string]("test", version)
+		if err != nil {
+			t.Fatalf("%s: NewHFSpaceForGradioVersion() returned error: %v", version, err)
+		}
+		if got := h.endpointURL("/predict"); got != want {
+			t.Errorf("%s: expected endpoint URL %s, got %s", version, want, got)
+		}
+	}
+	if _, err := NewHFSpaceForGradioVersion[string, string]("test", "latest"); err == nil {
+		t.Fatalf("expected an error for an invalid version")
+	}
+}
+
+func Test_EndpointBaseSync(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"data":["ok"],"duration":0.1}`))
+	}))
+	defer srv.Close()
+
+	h := NewHfs[string, string]("test")
+	h.BaseURL = srv.URL + "/gradio_api/call"
+	res, err := h.WithEndpointBase("/run").Do("/predict", "x")
+	if err != nil || len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("Do() returned %v, %v", res, err)
+	}
+	if len(paths) != 1 || paths[0] != "POST /run/predict" {
+		t.Fatalf("expected a single POST /run/predict, got %v", paths)
+	}
+}
@@ -0,0 +1,66 @@
+package hfs
+
+import (
+	"context"
+	"fmt"
+)
+
+// EndpointConfig describes an endpoint of a space for DoTyped(), e.g. as listed by Info().
+type EndpointConfig struct {
+	FnIndex     int      // sent as fn_index like WithFnIndex(), or left out if negative
+	InputTypes  []string // one entry per param, e.g. "str" or "filepath"
+	OutputTypes []string // one entry per output
+}
+
+// ErrParamCountMismatch is returned by DoTyped() when the number of params
+// differs from the InputTypes of the endpoint. Nothing is sent in that case.
+type ErrParamCountMismatch struct {
+	Endpoint      string
+	Got, Expected int
+}
+
+func (e *ErrParamCountMismatch) Error() string {
+	return fmt.Sprintf("hfs endpoint %s: got %d params, expected %d", e.Endpoint, e.Got, e.Expected)
+}
+
+// WithEndpoints describes the endpoints of a space with many of them, keyed by endpoint name
+// like "/predict", so that DoTyped() can check calls before sending them.
+func WithEndpoints(m map[string]*EndpointConfig) Option {
+	return func(c *config) {
+		c.endpoints = m
+	}
+}
+
+// DoTyped is DoWithContext() for an endpoint configured with WithEndpoints(). It fails with an
+// *ErrParamCountMismatch, before any network call, if params do not match its InputTypes.
+func (h *HFSpace[I, O]) DoTyped(ctx context.Context, name string, params ...I) ([]O, error) {
+	res, err := h.doTyped(ctx, name, params)
+	if err != nil && h.errorHandler != nil {
+		return h.errorHandler(err)
+	}
+	return res, err
+}
+
+func (h *HFSpace[I, O]) doTyped(ctx context.Context, name string, params []I) ([]O, error) {
+	ep, ok := h.endpoints[name]
+	if !ok || ep == nil {
+		return nil, fmt.Errorf("hfs endpoint %s not configured, see WithEndpoints", name)
+	}
+	if len(params) != len(ep.InputTypes) {
+		return nil, &ErrParamCountMismatch{Endpoint: name, Got: len(params), Expected: len(ep.InputTypes)}
+	}
+	if err := validateParams(params); err != nil {
+		return nil, err
+	}
+
+	payload := h.dataPayload(params)
+	delete(payload, "fn_index")
+	if ep.FnIndex >= 0 {
+		payload["fn_index"] = ep.FnIndex
+	}
+	data, err := h.callPayload(ctx, h.endpointURL(name), name, payload)
+	if err != nil {
+		return nil, err
+	}
+	return h.decodeResult(data)
+}
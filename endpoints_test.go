@@ -0,0 +1,56 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_DoTyped(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, r.URL.Path+" "+string(b))
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	hfs := newTestHfs[any, string](srv).WithEndpoints(map[string]*EndpointConfig{
+		"/generate": {FnIndex: 2, InputTypes: []string{"str", "float"}, OutputTypes: []string{"filepath"}},
+		"/caption":  {FnIndex: -1, InputTypes: []string{"filepath"}, OutputTypes: []string{"str"}},
+	})
+
+	res, err := hfs.DoTyped(context.Background(), "/generate", "a cat", 7.5)
+	if err != nil || len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("DoTyped returned %v, %v", res, err)
+	}
+	if _, err := hfs.DoTyped(context.Background(), "/caption", "cat.png"); err != nil {
+		t.Fatalf("DoTyped returned error: %v", err)
+	}
+	want := []string{
+		`/gradio_api/call/generate {"data":["a cat",7.5],"fn_index":2}`,
+		`/gradio_api/call/caption {"data":["cat.png"]}`,
+	}
+	if len(bodies) != 2 || bodies[0] != want[0] || bodies[1] != want[1] {
+		t.Fatalf("expected requests %q, got %q", want, bodies)
+	}
+
+	_, err = hfs.DoTyped(context.Background(), "/generate", "a cat")
+	var mismatch *ErrParamCountMismatch
+	if !errors.As(err, &mismatch) || mismatch.Got != 1 || mismatch.Expected != 2 {
+		t.Fatalf("expected ErrParamCountMismatch{Got: 1, Expected: 2}, got %v", err)
+	}
+	if _, err := hfs.DoTyped(context.Background(), "/unknown"); err == nil {
+		t.Fatalf("expected an error for an endpoint that is not configured")
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected failed checks not to send anything, got %d requests", len(bodies))
+	}
+}
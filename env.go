@@ -0,0 +1,64 @@
+package hfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// NewHFSpaceFromEnv creates an HFSpace configured by environment variables, so names and tokens
+// stay out of source code. With prefix "MYAPP" it reads:
+//
+//	MYAPP_SPACE_NAME       space name, as for NewHfs()
+//	MYAPP_BASE_URL         base URL, as for NewHFSpaceFromURL(); wins over MYAPP_SPACE_NAME
+//	MYAPP_HF_TOKEN         bearer token
+//	MYAPP_TIMEOUT_SECONDS  HTTP client timeout in seconds, e.g. "90" or "1.5"
+//	MYAPP_USER_AGENT       User-Agent header
+//
+// One of MYAPP_SPACE_NAME and MYAPP_BASE_URL is required. Empty optional variables keep the defaults.
+// opts are applied after the environment.
+func NewHFSpaceFromEnv[I, O any](prefix string, opts ...Option) (*HFSpace[I, O], error) {
+	key := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "_" + name
+	}
+	name := os.Getenv(key("SPACE_NAME"))
+	baseURL := os.Getenv(key("BASE_URL"))
+
+	var errs []error
+	if name == "" && baseURL == "" {
+		errs = append(errs, fmt.Errorf("missing %s or %s", key("SPACE_NAME"), key("BASE_URL")))
+	}
+	if baseURL != "" {
+		if err := validateBaseURL(baseURL); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key("BASE_URL"), err))
+		}
+	}
+
+	var envOpts []Option
+	if token := os.Getenv(key("HF_TOKEN")); token != "" {
+		envOpts = append(envOpts, WithBearerToken(token))
+	}
+	if s := os.Getenv(key("TIMEOUT_SECONDS")); s != "" {
+		secs, err := strconv.ParseFloat(s, 64)
+		if err != nil || secs <= 0 {
+			errs = append(errs, fmt.Errorf("%s: %q is not a positive number of seconds", key("TIMEOUT_SECONDS"), s))
+		}
+		envOpts = append(envOpts, WithTimeout(time.Duration(secs*float64(time.Second))))
+	}
+	if agent := os.Getenv(key("USER_AGENT")); agent != "" {
+		envOpts = append(envOpts, WithUserAgent(agent))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("hfs env config: %w", errors.Join(errs...))
+	}
+
+	if baseURL != "" {
+		envOpts = append(envOpts, WithBaseURL(baseURL))
+	}
+	return NewHfs[I, O](name, append(envOpts, opts...)...), nil
+}
@@ -0,0 +1,73 @@
+package hfs
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setEnv sets the given variables for the test and unsets every other HFS_TEST_ variable.
+func setEnv(t *testing.T, vars map[string]string) {
+	t.Helper()
+	for _, name := range []string{"SPACE_NAME", "HF_TOKEN", "TIMEOUT_SECONDS", "BASE_URL", "USER_AGENT"} {
+		key := "HFS_TEST_" + name
+		t.Setenv(key, "")
+		if v, ok := vars[key]; ok {
+			os.Setenv(key, v)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func Test_NewHFSpaceFromEnv(t *testing.T) {
+	t.Run("all", func(t *testing.T) {
+		setEnv(t, map[string]string{
+			"HFS_TEST_SPACE_NAME":      "owner-app",
+			"HFS_TEST_HF_TOKEN":        "hf_x",
+			"HFS_TEST_TIMEOUT_SECONDS": "1.5",
+			"HFS_TEST_USER_AGENT":      "hfs-test",
+		})
+		hfs, err := NewHFSpaceFromEnv[any, any]("HFS_TEST")
+		if err != nil {
+			t.Fatalf("NewHFSpaceFromEnv() returned error: %v", err)
+		}
+		if hfs.BaseURL != "https://owner-app.hf.space/gradio_api/call" {
+			t.Fatalf("unexpected BaseURL %s", hfs.BaseURL)
+		}
+		if hfs.Headers["Authorization"] != "Bearer hf_x" || hfs.Headers["User-Agent"] != "hfs-test" {
+			t.Fatalf("unexpected headers %v", hfs.Headers)
+		}
+		if hfs.client.Timeout != 1500*time.Millisecond {
+			t.Fatalf("expected 1.5s timeout, got %v", hfs.client.Timeout)
+		}
+	})
+
+	t.Run("base url", func(t *testing.T) {
+		setEnv(t, map[string]string{"HFS_TEST_BASE_URL": "http://localhost:7860/gradio_api/call/"})
+		hfs, err := NewHFSpaceFromEnv[any, any]("HFS_TEST", WithTimeout(time.Minute))
+		if err != nil {
+			t.Fatalf("NewHFSpaceFromEnv() returned error: %v", err)
+		}
+		if hfs.BaseURL != "http://localhost:7860/gradio_api/call" {
+			t.Fatalf("unexpected BaseURL %s", hfs.BaseURL)
+		}
+		if _, ok := hfs.Headers["Authorization"]; ok || hfs.client.Timeout != time.Minute {
+			t.Fatalf("expected defaults plus options, got headers %v and timeout %v", hfs.Headers, hfs.client.Timeout)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		setEnv(t, map[string]string{"HFS_TEST_TIMEOUT_SECONDS": "soon"})
+		_, err := NewHFSpaceFromEnv[any, any]("HFS_TEST")
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		for _, want := range []string{"HFS_TEST_SPACE_NAME", "HFS_TEST_BASE_URL", "HFS_TEST_TIMEOUT_SECONDS"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Fatalf("expected error to mention %s, got %v", want, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package hfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors. Errors returned by this package wrap one of these where applicable,
+// so callers can use errors.Is instead of matching messages.
+var (
+	ErrNoData           = errors.New("hfs no data in resp")
+	ErrEventError       = errors.New("hfs event error")
+	ErrDecodeFailure    = errors.New("hfs decode failure")
+	ErrUploadFailure    = errors.New("hfs upload failure")
+	ErrRateLimited      = errors.New("hfs rate limited")
+	ErrSchemaValidation = errors.New("hfs schema validation failed")
+	ErrIdleTimeout      = errors.New("hfs idle timeout")
+
+	ErrUnserializableParam = errors.New("hfs unserializable param")
+	ErrHTTPStatus          = errors.New("hfs unexpected http status")
+	ErrEmptyContent        = errors.New("hfs downloaded content is empty")
+	ErrInvalidFileData     = errors.New("hfs invalid FileData")
+	ErrCircuitOpen         = errors.New("hfs circuit breaker open")
+	ErrSpaceSleeping       = errors.New("hfs space is sleeping")
+	ErrNotSupported        = errors.New("hfs operation not supported")
+	ErrResponseTooLarge    = errors.New("hfs response too large")
+	ErrNoMoreReplays       = errors.New("hfs no more replays")
+	ErrQueueFull           = errors.New("hfs queue full")
+	ErrEndpointNotFound    = errors.New("hfs endpoint not found")
+
+	// ErrShuttingDown is reserved for requests refused because the client is shutting down.
+	// Nothing returns it yet, as HFSpace has no shutdown; match it with errors.Is to be ready for one.
+	ErrShuttingDown = errors.New("hfs shutting down")
+
+	// ErrUploadFailed is an alias of ErrUploadFailure.
+	ErrUploadFailed = ErrUploadFailure
+
+	// ErrGeneratingTimeout is an ErrIdleTimeout for streams that stop emitting "generating" events.
+	ErrGeneratingTimeout = fmt.Errorf("hfs generating timeout: %w", ErrIdleTimeout)
+)
+
+// EventError is returned when the event stream of a request reports an error.
+// Err holds the *GradioError sent along with it, if any; use errors.As to get it.
+type EventError struct {
+	EventID string
+	Err     error
+}
+
+func (e *EventError) Error() string {
+	if e.Err != nil {
+		return "hfs event error: " + e.Err.Error()
+	}
+	return "hfs event error"
+}
+
+func (e *EventError) Is(target error) bool {
+	return target == ErrEventError
+}
+
+func (e *EventError) Unwrap() error {
+	return e.Err
+}
+
+// GradioError is the detail a space sends with an "error" event.
+type GradioError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func (e *GradioError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("gradio error %d: %s", e.Code, e.Message)
+	}
+	return "gradio error: " + e.Message
+}
+
+// newEventError builds the error for an "error" event carrying data.
+// The detail is only attached if data is a JSON object with a message.
+func newEventError(eventID, data string) *EventError {
+	var detail struct {
+		Message *string         `json:"message"`
+		Error   *string         `json:"error"`
+		Code    json.RawMessage `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(data), &detail); err != nil {
+		return &EventError{EventID: eventID}
+	}
+	gerr := &GradioError{}
+	switch {
+	case detail.Message != nil:
+		gerr.Message = *detail.Message
+	case detail.Error != nil:
+		gerr.Message = *detail.Error
+	default:
+		return &EventError{EventID: eventID}
+	}
+	json.Unmarshal(detail.Code, &gerr.Code) // the code is optional and not always numeric
+	return &EventError{EventID: eventID, Err: gerr}
+}
+
+// HTTPStatusError is returned when a server replies with a non-2xx status.
+// A 429 also matches ErrRateLimited.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return "hfs http status " + e.Status
+}
+
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrHTTPStatus || (target == ErrRateLimited && e.StatusCode == 429)
+}
+
+// checkStatus returns an *HTTPStatusError if resp is not 2xx.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return nil
+}
+
+// BatchUploadError is returned by Quax.BatchUpload when some of the files were not uploaded.
+// It matches ErrUploadFailure.
+type BatchUploadError struct {
+	Failed []int // indices of the failed files
+	Err    error // why, if known
+}
+
+func (e *BatchUploadError) Error() string {
+	msg := fmt.Sprintf("quax batch upload failed for files %v", e.Failed)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *BatchUploadError) Is(target error) bool {
+	return target == ErrUploadFailure
+}
+
+func (e *BatchUploadError) Unwrap() error {
+	return e.Err
+}
+
+// kindError tags err with a sentinel kind without changing its message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind makes errors.Is(err, kind) true while keeping err's message and chain.
+func withKind(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
@@ -0,0 +1,184 @@
+package hfs
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc fakes an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func Test_ErrorKinds(t *testing.T) {
+	cases := []struct {
+		name string
+		sse  string
+		kind error
+	}{
+		{"no data", "event: complete\n\n", ErrNoData},
+		{"event error", "event: error\ndata: null\n\n", ErrEventError},
+		{"decode failure", "event: complete\ndata: {not json\n\n", ErrDecodeFailure},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := fakeGradio(t, c.sse, nil)
+			_, err := newTestHfs[any, any](srv).Do(test_endpoint)
+			if !errors.Is(err, c.kind) {
+				t.Fatalf("expected errors.Is(%v, %v)", err, c.kind)
+			}
+		})
+	}
+
+	t.Run("event error as", func(t *testing.T) {
+		srv := fakeGradio(t, "event: error\ndata: null\n\n", nil)
+		_, err := newTestHfs[any, any](srv).Do(test_endpoint)
+		var eerr *EventError
+		if !errors.As(err, &eerr) || eerr.EventID != "evt" {
+			t.Fatalf("expected *EventError for evt, got %v", err)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		}))
+		defer srv.Close()
+		_, err := newTestHfs[any, any](srv).Do(test_endpoint)
+		if !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("expected ErrRateLimited, got %v", err)
+		}
+	})
+
+	t.Run("schema validation", func(t *testing.T) {
+		srv := fakeGradio(t, "event: complete\ndata: [1]\n\n", nil)
+		_, err := newTestHfs[any, any](srv).WithOutputSchema([]byte(`{"items": {"type": "string"}}`)).Do(test_endpoint)
+		var verr *SchemaValidationError
+		if !errors.Is(err, ErrSchemaValidation) || !errors.As(err, &verr) {
+			t.Fatalf("expected ErrSchemaValidation, got %v", err)
+		}
+	})
+
+	t.Run("upload failure", func(t *testing.T) {
+		q := NewQuax().WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			io.Copy(io.Discard, r.Body)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"success":false}`)),
+			}, nil
+		})})
+		_, err := q.Upload([]byte("data"), "a.txt")
+		if !errors.Is(err, ErrUploadFailure) || !errors.Is(err, ErrUploadFailed) {
+			t.Fatalf("expected ErrUploadFailure, got %v", err)
+		}
+	})
+
+	t.Run("invalid FileData", func(t *testing.T) {
+		if _, err := GetFileData((*FileData)(nil)); !errors.Is(err, ErrInvalidFileData) {
+			t.Fatalf("expected ErrInvalidFileData for a nil *FileData, got %v", err)
+		}
+		if _, err := FileDataReader(nil, nil, nil); !errors.Is(err, ErrInvalidFileData) {
+			t.Fatalf("expected ErrInvalidFileData for no FileData, got %v", err)
+		}
+		if _, err := FileDataReader(&FileData{}, nil, nil); !errors.Is(err, ErrInvalidFileData) {
+			t.Fatalf("expected ErrInvalidFileData for a FileData without URL, got %v", err)
+		}
+	})
+
+	t.Run("base64 decode", func(t *testing.T) {
+		_, err := NewFileData("").FromBase64("!!!")
+		if !errors.Is(err, ErrDecodeFailure) {
+			t.Fatalf("expected ErrDecodeFailure, got %v", err)
+		}
+	})
+}
+
+func Test_HTTPStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/missing"):
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"event_id":"evt"}`))
+		case strings.HasSuffix(r.URL.Path, "/empty"):
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, any](srv)
+
+	cases := []struct {
+		endpoint string
+		status   int
+	}{
+		{"/missing", http.StatusNotFound},
+		{"/broken", http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		_, err := hfs.Do(c.endpoint)
+		var herr *HTTPStatusError
+		if !errors.Is(err, ErrHTTPStatus) || !errors.As(err, &herr) {
+			t.Fatalf("%s: expected *HTTPStatusError, got %v", c.endpoint, err)
+		}
+		if herr.StatusCode != c.status {
+			t.Fatalf("%s: expected status %d, got %d", c.endpoint, c.status, herr.StatusCode)
+		}
+		if errors.Is(err, ErrRateLimited) {
+			t.Fatalf("%s: status %d must not match ErrRateLimited", c.endpoint, c.status)
+		}
+	}
+
+	fd, _ := NewFileData("").FromUrl(srv.URL + "/empty")
+	if _, err := GetFileData(fd); !errors.Is(err, ErrEmptyContent) {
+		t.Fatalf("expected ErrEmptyContent, got %v", err)
+	}
+	fd, _ = NewFileData("").FromUrl(srv.URL + "/gone")
+	_, err := GetFileData(fd)
+	var herr *HTTPStatusError
+	if !errors.As(err, &herr) || herr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected *HTTPStatusError for download, got %v", err)
+	}
+}
+
+func Test_GradioError(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want *GradioError
+	}{
+		{"message and code", `{"message":"CUDA out of memory","code":500}`, &GradioError{Message: "CUDA out of memory", Code: 500}},
+		{"error field", `{"error":"Queue is full"}`, &GradioError{Message: "Queue is full"}},
+		{"null", `null`, nil},
+		{"no message", `{"error":null}`, nil},
+		{"not json", `oops`, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := fakeGradio(t, "event: error\ndata: "+c.data+"\n\n", nil)
+			_, err := newTestHfs[any, any](srv).Do(test_endpoint)
+			if !errors.Is(err, ErrEventError) {
+				t.Fatalf("expected ErrEventError, got %v", err)
+			}
+			var gerr *GradioError
+			if c.want == nil {
+				if errors.As(err, &gerr) || err.Error() != "hfs event error" {
+					t.Fatalf("expected generic event error, got %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &gerr) || *gerr != *c.want {
+				t.Fatalf("expected %+v, got %v", c.want, err)
+			}
+			if !strings.Contains(err.Error(), c.want.Message) {
+				t.Fatalf("expected message in %q", err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package hfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventStatus is the state of an event submitted with Submit() and not yet collected with FetchResult().
+type EventStatus struct {
+	EventID string
+	// QueuePosition is the number of events ahead in the queue, -1 if the server did not report it.
+	QueuePosition int
+	// ETA is the server's estimate until the result is ready, 0 if it did not report one.
+	ETA    time.Duration
+	Status string // "queued" if the server ranked the event, otherwise "pending"
+}
+
+// queueEstimation is the reply of /gradio_api/queue/status. Gradio only fills in rank and rank_eta
+// for messages about a specific event; the endpoint itself usually just reports the queue size.
+type queueEstimation struct {
+	Msg       string   `json:"msg"`
+	EventID   *string  `json:"event_id"`
+	Rank      *int     `json:"rank"`
+	QueueSize int      `json:"queue_size"`
+	RankETA   *float64 `json:"rank_eta"`
+}
+
+// ListEvents returns the status of the events submitted to endpoint with Submit()
+// whose results have not been fetched yet, ordered by event ID.
+// It asks the server's queue status endpoint for positions; events it does not
+// mention are reported as "pending" with an unknown position.
+func (h *HFSpace[I, O]) ListEvents(ctx context.Context, endpoint string) ([]EventStatus, error) {
+	est, err := h.queueStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fullURL := h.endpointURL(endpoint)
+	var events []EventStatus
+	h.pending.Range(func(id, url any) bool {
+		if url != fullURL {
+			return true
+		}
+		ev := EventStatus{EventID: id.(string), QueuePosition: -1, Status: "pending"}
+		if est.EventID != nil && *est.EventID == ev.EventID && est.Rank != nil {
+			ev.QueuePosition = *est.Rank
+			ev.Status = "queued"
+			if est.RankETA != nil {
+				ev.ETA = time.Duration(*est.RankETA * float64(time.Second))
+			}
+		}
+		events = append(events, ev)
+		return true
+	})
+	sort.Slice(events, func(i, j int) bool { return events[i].EventID < events[j].EventID })
+	return events, nil
+}
+
+// QueueSize returns the number of events waiting in the queue of the space, e.g. to hold back
+// submissions while it is long. See WithMaxQueueDepth() for doing so automatically.
+func (h *HFSpace[I, O]) QueueSize(ctx context.Context) (int, error) {
+	est, err := h.queueStatus(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return est.QueueSize, nil
+}
+
+// WithMaxQueueDepth makes Do() and its variants check QueueSize() first and fail with ErrQueueFull,
+// without submitting anything, while more than n events are waiting. A failure to get the
+// queue size fails the request too. 0 turns the check off.
+func WithMaxQueueDepth(n int) Option {
+	return func(c *config) {
+		c.maxQueueDepth = n
+	}
+}
+
+// checkQueueDepth enforces WithMaxQueueDepth.
+func (h *HFSpace[I, O]) checkQueueDepth(ctx context.Context) error {
+	if h.maxQueueDepth <= 0 {
+		return nil
+	}
+	size, err := h.QueueSize(ctx)
+	if err != nil {
+		return err
+	}
+	if size > h.maxQueueDepth {
+		return fmt.Errorf("hfs queue size %d over %d: %w", size, h.maxQueueDepth, ErrQueueFull)
+	}
+	return nil
+}
+
+// queueStatus fetches the reply of the queue status endpoint.
+func (h *HFSpace[I, O]) queueStatus(ctx context.Context) (*queueEstimation, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", h.queueStatusURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("hfs queue status req create: %w", err)
+	}
+	h.applyHeaders(req.Header)
+
+	resp, err := h.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("hfs queue status req exec: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return nil, fmt.Errorf("hfs queue status resp: %w", err)
+	}
+	var est queueEstimation
+	if err := json.NewDecoder(resp.Body).Decode(&est); err != nil {
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("hfs queue status decode: %w", err))
+	}
+	return &est, nil
+}
+
+// queueStatusURL turns a BaseURL like ".../gradio_api/call" into ".../gradio_api/queue/status".
+// The synchronous APIs serve it at the root.
+func (h *HFSpace[I, O]) queueStatusURL() string {
+	if h.syncAPI() {
+		return h.rootURL() + "queue/status"
+	}
+	return strings.TrimSuffix(h.BaseURL, "/call") + "/queue/status"
+}
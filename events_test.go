@@ -0,0 +1,99 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_ListEvents(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/queue_status.json")
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	var ids atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/gradio_api/queue/status":
+			w.Write(fixture)
+		case r.Method == http.MethodPost:
+			fmt.Fprintf(w, `{"event_id":"evt%d"}`, ids.Add(1))
+		default:
+			w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+		}
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, string](srv)
+	ctx := context.Background()
+
+	for _, endpoint := range []string{"/predict", "/predict", "/other"} {
+		if _, err := hfs.Submit(ctx, endpoint); err != nil {
+			t.Fatalf("Submit() returned error: %v", err)
+		}
+	}
+	events, err := hfs.ListEvents(ctx, "/predict")
+	if err != nil {
+		t.Fatalf("ListEvents() returned error: %v", err)
+	}
+	want := []EventStatus{
+		{EventID: "evt1", QueuePosition: -1, Status: "pending"},
+		{EventID: "evt2", QueuePosition: 1, ETA: 3500 * time.Millisecond, Status: "queued"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %+v, got %+v", want, events)
+	}
+
+	if _, err := hfs.FetchResult(ctx, "/predict", "evt1"); err != nil {
+		t.Fatalf("FetchResult() returned error: %v", err)
+	}
+	events, err = hfs.ListEvents(ctx, "/predict")
+	if err != nil {
+		t.Fatalf("ListEvents() returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].EventID != "evt2" {
+		t.Fatalf("expected only evt2 left after fetching evt1, got %+v", events)
+	}
+}
+
+func Test_QueueSize(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/queue_status.json")
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/gradio_api/queue/status":
+			w.Write(fixture)
+		case r.Method == http.MethodPost:
+			posts.Add(1)
+			w.Write([]byte(`{"event_id":"evt"}`))
+		default:
+			w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+		}
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, string](srv)
+
+	size, err := hfs.QueueSize(context.Background())
+	if err != nil || size != 3 {
+		t.Fatalf("QueueSize() returned %d, %v", size, err)
+	}
+
+	if _, err := hfs.WithMaxQueueDepth(3).Do("/predict", "x"); err != nil {
+		t.Fatalf("expected a queue of 3 to be accepted, got %v", err)
+	}
+	if _, err := hfs.WithMaxQueueDepth(2).Do("/predict", "x"); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if n := posts.Load(); n != 1 {
+		t.Fatalf("expected nothing submitted to a full queue, got %d POSTs", n)
+	}
+}
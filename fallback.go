@@ -0,0 +1,43 @@
+package hfs
+
+import (
+	"context"
+	"fmt"
+)
+
+// FallbackError is returned by DoWithFallback when both endpoints failed.
+type FallbackError struct {
+	Primary  error
+	Fallback error
+}
+
+func (e *FallbackError) Error() string {
+	return fmt.Sprintf("hfs primary endpoint: %v; fallback endpoint: %v", e.Primary, e.Fallback)
+}
+
+func (e *FallbackError) Unwrap() []error {
+	return []error{e.Primary, e.Fallback}
+}
+
+// DoWithFallback is DoWithContext() on primary, e.g. a fast GPU endpoint, that calls fallback
+// with the same params if primary fails for any reason. If fallback fails too, the error is a
+// *FallbackError holding both errors; otherwise the result of whichever succeeded is returned.
+func (h *HFSpace[I, O]) DoWithFallback(ctx context.Context, primary, fallback string, params ...I) ([]O, error) {
+	res, err := h.doWithFallback(ctx, primary, fallback, params)
+	if err != nil && h.errorHandler != nil {
+		return h.errorHandler(err)
+	}
+	return res, err
+}
+
+func (h *HFSpace[I, O]) doWithFallback(ctx context.Context, primary, fallback string, params []I) ([]O, error) {
+	res, perr := h.call(ctx, primary, params)
+	if perr == nil {
+		return res, nil
+	}
+	res, ferr := h.call(ctx, fallback, params)
+	if ferr != nil {
+		return nil, &FallbackError{Primary: perr, Fallback: ferr}
+	}
+	return res, nil
+}
@@ -0,0 +1,55 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func Test_DoWithFallback(t *testing.T) {
+	var posts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts = append(posts, r.URL.Path)
+			switch r.URL.Path {
+			case "/gradio_api/call/gpu", "/gradio_api/call/broken":
+				http.Error(w, "no gpu", http.StatusServiceUnavailable)
+			default:
+				w.Write([]byte(`{"event_id":"evt"}`))
+			}
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"from " + r.URL.Path + "\"]\n\n"))
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, string](srv)
+	ctx := context.Background()
+
+	res, err := hfs.DoWithFallback(ctx, "/gpu", "/cpu", "x")
+	if err != nil || res[0] != "from /gradio_api/call/cpu/evt" {
+		t.Fatalf("expected the fallback result, got %v, %v", res, err)
+	}
+	if want := []string{"/gradio_api/call/gpu", "/gradio_api/call/cpu"}; !reflect.DeepEqual(posts, want) {
+		t.Fatalf("expected POSTs %v, got %v", want, posts)
+	}
+
+	posts = nil
+	if _, err := hfs.DoWithFallback(ctx, "/cpu", "/gpu", "x"); err != nil {
+		t.Fatalf("DoWithFallback returned error: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("expected no fallback after the primary succeeded, got POSTs %v", posts)
+	}
+
+	_, err = hfs.DoWithFallback(ctx, "/gpu", "/broken", "x")
+	var ferr *FallbackError
+	if !errors.As(err, &ferr) || !errors.Is(ferr.Primary, ErrHTTPStatus) || !errors.Is(ferr.Fallback, ErrHTTPStatus) {
+		t.Fatalf("expected a FallbackError with both errors, got %v", err)
+	}
+	if !errors.Is(err, ErrHTTPStatus) {
+		t.Fatalf("expected the FallbackError to unwrap to its causes")
+	}
+}
@@ -0,0 +1,100 @@
+package hfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// GradioValue is one element of a mixed Gradio output such as (FileData, string, float64).
+// Value holds a string, float64, bool, *FileData, []any, map[string]any or nil.
+type GradioValue struct {
+	Value any
+}
+
+// DoGradio is like Do() but decodes each output element into a GradioValue,
+// so mixed outputs can be read without type assertions.
+func (h *HFSpace[I, O]) DoGradio(ctx context.Context, endpoint string, params ...I) ([]GradioValue, error) {
+	data, err := h.callRaw(ctx, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+	var values []GradioValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("hfs decode final resp: %w", err))
+	}
+	for i := range values {
+		h.authorizeOutput(&values[i])
+	}
+	return values, nil
+}
+
+func (v *GradioValue) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if obj, ok := raw.(map[string]any); ok && isFileData(obj) {
+		var fd FileData
+		if err := json.Unmarshal(b, &fd); err != nil {
+			return err
+		}
+		v.Value = &fd
+		return nil
+	}
+	v.Value = raw
+	return nil
+}
+
+func (v GradioValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Value)
+}
+
+// isFileData reports whether a decoded JSON object looks like a Gradio FileData.
+func isFileData(obj map[string]any) bool {
+	if meta, ok := obj["meta"].(map[string]any); ok && meta["_type"] == "gradio.FileData" {
+		return true
+	}
+	_, hasPath := obj["path"]
+	_, hasURL := obj["url"]
+	return hasPath && hasURL
+}
+
+// Type returns "string", "number", "bool", "filedata", "array", "object" or "null".
+func (v GradioValue) Type() string {
+	switch v.Value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case *FileData:
+		return "filedata"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "null"
+}
+
+func (v GradioValue) String() (string, bool) {
+	s, ok := v.Value.(string)
+	return s, ok
+}
+
+func (v GradioValue) Number() (float64, bool) {
+	n, ok := v.Value.(float64)
+	return n, ok
+}
+
+func (v GradioValue) Bool() (bool, bool) {
+	b, ok := v.Value.(bool)
+	return b, ok
+}
+
+func (v GradioValue) FileData() (*FileData, bool) {
+	fd, ok := v.Value.(*FileData)
+	return fd, ok
+}
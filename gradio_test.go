@@ -0,0 +1,41 @@
+package hfs
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_DoGradio(t *testing.T) {
+	sse := "event: complete\n" +
+		`data: [{"path": "/tmp/a.png", "url": "https://x/a.png", "meta": {"_type": "gradio.FileData"}}, "caption", 0.5, true, [1, 2], {"k": "v"}, null]` +
+		"\n\n"
+	srv := fakeGradio(t, sse, nil)
+
+	values, err := newTestHfs[any, any](srv).DoGradio(context.Background(), test_endpoint)
+	if err != nil {
+		t.Fatalf("DoGradio() returned error: %v", err)
+	}
+	want := []string{"filedata", "string", "number", "bool", "array", "object", "null"}
+	if len(values) != len(want) {
+		t.Fatalf("expected %d values, got %d", len(want), len(values))
+	}
+	for i, v := range values {
+		if v.Type() != want[i] {
+			t.Fatalf("value %d: expected type %s, got %s", i, want[i], v.Type())
+		}
+	}
+
+	fd, ok := values[0].FileData()
+	if !ok || fd.URL != "https://x/a.png" {
+		t.Fatalf("expected FileData with URL, got %v", values[0].Value)
+	}
+	if s, ok := values[1].String(); !ok || s != "caption" {
+		t.Fatalf("expected string caption, got %v", values[1].Value)
+	}
+	if n, ok := values[2].Number(); !ok || n != 0.5 {
+		t.Fatalf("expected number 0.5, got %v", values[2].Value)
+	}
+	if _, ok := values[1].Number(); ok {
+		t.Fatalf("expected Number() to fail on a string")
+	}
+}
@@ -0,0 +1,61 @@
+package hfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_WithGzip(t *testing.T) {
+	input := strings.Repeat("a", 10*1024)
+	var wireSize int
+	var received []any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected Accept-Encoding: gzip, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		if r.Method == http.MethodPost {
+			raw, _ := io.ReadAll(r.Body)
+			wireSize = len(raw)
+			if r.Header.Get("Content-Encoding") != "gzip" {
+				t.Errorf("expected Content-Encoding: gzip, got %q", r.Header.Get("Content-Encoding"))
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				t.Errorf("gzip.NewReader() returned error: %v", err)
+				return
+			}
+			var payload struct{ Data []any }
+			if err := json.NewDecoder(zr).Decode(&payload); err != nil {
+				t.Errorf("decoding the body returned error: %v", err)
+			}
+			received = payload.Data
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	out, err := newTestHfs[string, string](srv).WithGzip().Do("/predict", input)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if len(out) != 1 || out[0] != "ok" {
+		t.Fatalf("expected the gzip-encoded response to be decompressed, got %q", out)
+	}
+	if len(received) != 1 || received[0] != input {
+		t.Fatalf("expected the input to arrive intact, got %d values", len(received))
+	}
+	if wireSize == 0 || wireSize >= len(input) {
+		t.Fatalf("expected fewer than %d bytes on the wire, got %d", len(input), wireSize)
+	}
+}
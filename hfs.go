@@ -2,157 +2,1124 @@ package hfs
 
 import (
 	"bytes"
+	"container/list"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
+	mrand "math/rand/v2"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 // HFSpace represents a client to a Hugging Face Space.
 // I is the input type, O is the output type. Use `any` if there are different types.
 // Use NewHfs() to create an instance.
 type HFSpace[I any, O any] struct {
-	BaseURL string
-	Headers map[string]string
-	client  *http.Client
+	config
+
+	dedup        sync.Map // [sha256.Size]byte -> *dedupEntry
+	dedupLRU     dedupLRU // completed entries of dedup, for WithDeduplicationMaxSize()
+	pending      sync.Map // event ID -> endpoint URL, for ListEvents()
+	errorHandler func(err error) ([]O, error)
+	warmedUp     atomic.Bool               // the first request has run, for WithAutoWarmup()
+	apiSchema    atomic.Pointer[APISchema] // cached by Schema()
 }
 
-// NewHfs creates a new HFSpace with a default HTTP client.
+// retryPolicy configures retries of the full POST + GET round trip.
+type retryPolicy struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	multiplier   float64
+	jitter       float64
+	callback     func(attempt int, err error)
+}
+
+// dedupEntry holds the shared outcome of one deduplicated request.
+type dedupEntry struct {
+	key  [sha256.Size]byte
+	done chan struct{}
+	data []byte
+	err  error
+	elem *list.Element // in dedupLRU once completed, guarded by its mutex
+}
+
+// NewHfs creates a new HFSpace with its own HTTP client, configured by opts.
 // I is the input type, O is the output type. Use `any` if there are different types.
-func NewHfs[I, O any](Name string) *HFSpace[I, O] {
-	return &HFSpace[I, O]{
-		BaseURL: "https://" + Name + ".hf.space/gradio_api/call",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		client: http.DefaultClient,
+func NewHfs[I, O any](Name string, opts ...Option) *HFSpace[I, O] {
+	h := &HFSpace[I, O]{config: defaultConfig()}
+	h.BaseURL = "https://" + Name + ".hf.space/gradio_api/call"
+	return h.apply(opts...)
+}
+
+// NewHFSpaceByName is NewHfs(): it targets https://<name>.hf.space.
+func NewHFSpaceByName[I, O any](name string, opts ...Option) *HFSpace[I, O] {
+	return NewHfs[I, O](name, opts...)
+}
+
+// NewHFSpaceFromURL creates an HFSpace for a self-hosted or local Gradio deployment.
+// rawURL is used verbatim as BaseURL, e.g. "http://localhost:7860/gradio_api/call",
+// and must have a scheme and a host.
+func NewHFSpaceFromURL[I, O any](rawURL string, opts ...Option) (*HFSpace[I, O], error) {
+	if err := validateBaseURL(rawURL); err != nil {
+		return nil, err
 	}
+	h := &HFSpace[I, O]{config: defaultConfig()}
+	return h.apply(append([]Option{WithBaseURL(rawURL)}, opts...)...), nil
 }
 
-// WithHeader sets a custom header.
-func (h *HFSpace[I, O]) WithHeader(key, value string) *HFSpace[I, O] {
-	h.Headers[key] = value
+func validateBaseURL(rawURL string) error {
+	uri, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("hfs base url parse: %w", err)
+	}
+	if uri.Scheme == "" || uri.Host == "" {
+		return fmt.Errorf("hfs base url %q must have a scheme and a host", rawURL)
+	}
+	return nil
+}
+
+// apply applies opts in order.
+func (h *HFSpace[I, O]) apply(opts ...Option) *HFSpace[I, O] {
+	for _, opt := range opts {
+		opt(&h.config)
+	}
 	return h
 }
 
-// WithBearerToken adds an Authorization Bearer token.
+// Clone returns an independent copy of h that can be reconfigured without affecting h,
+// e.g. to use another token. Headers are copied; the http.Client is shared, as it is safe
+// for concurrent use, until an option on either side replaces it. The clone starts with
+// its own rate limiter and circuit breaker state, and no deduplicated or pending requests.
+func (h *HFSpace[I, O]) Clone() *HFSpace[I, O] {
+	c := &HFSpace[I, O]{config: h.config, errorHandler: h.errorHandler}
+	c.Headers = maps.Clone(h.Headers)
+	c.transforms = slices.Clone(h.transforms)
+	if h.limiter != nil {
+		c.limiter = rate.NewLimiter(h.limiter.Limit(), h.limiter.Burst())
+	}
+	if h.breaker != nil {
+		c.breaker = newCircuitBreaker(h.breaker.threshold, h.breaker.resetAfter)
+	}
+	return c
+}
+
+// WithHeader applies the WithHeader option.
+func (h *HFSpace[I, O]) WithHeader(key, value string) *HFSpace[I, O] {
+	return h.apply(WithHeader(key, value))
+}
+
+// WithHeaderFunc applies the WithHeaderFunc option.
+func (h *HFSpace[I, O]) WithHeaderFunc(key string, fn func() string) *HFSpace[I, O] {
+	return h.apply(WithHeaderFunc(key, fn))
+}
+
+// WithBaseURL applies the WithBaseURL option.
+func (h *HFSpace[I, O]) WithBaseURL(rawURL string) *HFSpace[I, O] {
+	return h.apply(WithBaseURL(rawURL))
+}
+
+// WithBearerToken applies the WithBearerToken option.
 func (h *HFSpace[I, O]) WithBearerToken(token string) *HFSpace[I, O] {
-	return h.WithHeader("Authorization", "Bearer "+token)
+	return h.apply(WithBearerToken(token))
 }
 
-// WithTimeout sets a custom timeout on the underlying HTTP client.
-// Applies to both POST and GET requests.
+// WithTimeout applies the WithTimeout option.
 func (h *HFSpace[I, O]) WithTimeout(d time.Duration) *HFSpace[I, O] {
-	h.client.Timeout = d
-	return h
+	return h.apply(WithTimeout(d))
 }
 
-// WithUserAgent sets a custom User-Agent.
+// WithUserAgent applies the WithUserAgent option.
 func (h *HFSpace[I, O]) WithUserAgent(agent string) *HFSpace[I, O] {
-	return h.WithHeader("User-Agent", agent)
+	return h.apply(WithUserAgent(agent))
 }
 
-// WithHTTPClient allows setting a custom http.Client.
+// WithHTTPClient applies the WithHTTPClient option.
 func (h *HFSpace[I, O]) WithHTTPClient(client *http.Client) *HFSpace[I, O] {
-	h.client = client
+	return h.apply(WithHTTPClient(client))
+}
+
+// WithProxy applies the WithProxy option.
+func (h *HFSpace[I, O]) WithProxy(rawURL string) *HFSpace[I, O] {
+	return h.apply(WithProxy(rawURL))
+}
+
+// WithProxyE is WithProxy() returning a malformed rawURL as an error instead of failing every request.
+func (h *HFSpace[I, O]) WithProxyE(rawURL string) (*HFSpace[I, O], error) {
+	if err := h.setProxy(rawURL); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// WithTLSConfig applies the WithTLSConfig option.
+func (h *HFSpace[I, O]) WithTLSConfig(cfg *tls.Config) *HFSpace[I, O] {
+	return h.apply(WithTLSConfig(cfg))
+}
+
+// WithRateLimit applies the WithRateLimit option.
+func (h *HFSpace[I, O]) WithRateLimit(rps float64) *HFSpace[I, O] {
+	return h.apply(WithRateLimit(rps))
+}
+
+// WithRateLimitBurst applies the WithRateLimitBurst option.
+func (h *HFSpace[I, O]) WithRateLimitBurst(b int) *HFSpace[I, O] {
+	return h.apply(WithRateLimitBurst(b))
+}
+
+// WithEventIDField applies the WithEventIDField option.
+func (h *HFSpace[I, O]) WithEventIDField(fieldName string) *HFSpace[I, O] {
+	return h.apply(WithEventIDField(fieldName))
+}
+
+// WithOutputSchema applies the WithOutputSchema option.
+func (h *HFSpace[I, O]) WithOutputSchema(schema []byte) *HFSpace[I, O] {
+	return h.apply(WithOutputSchema(schema))
+}
+
+// WithProgressCallback applies the WithProgressCallback option.
+func (h *HFSpace[I, O]) WithProgressCallback(fn func(p ProgressEvent)) *HFSpace[I, O] {
+	return h.apply(WithProgressCallback(fn))
+}
+
+// WithClientGeneratedEventIDs applies the WithClientGeneratedEventIDs option.
+func (h *HFSpace[I, O]) WithClientGeneratedEventIDs() *HFSpace[I, O] {
+	return h.apply(WithClientGeneratedEventIDs())
+}
+
+// WithRequestTransform applies the WithRequestTransform option.
+func (h *HFSpace[I, O]) WithRequestTransform(fn func(*http.Request) (*http.Request, error)) *HFSpace[I, O] {
+	return h.apply(WithRequestTransform(fn))
+}
+
+// WithHTTPCacheControl applies the WithHTTPCacheControl option.
+func (h *HFSpace[I, O]) WithHTTPCacheControl(cache HTTPCache) *HFSpace[I, O] {
+	return h.apply(WithHTTPCacheControl(cache))
+}
+
+// WithConnectionReuse applies the WithConnectionReuse option.
+func (h *HFSpace[I, O]) WithConnectionReuse() *HFSpace[I, O] {
+	return h.apply(WithConnectionReuse())
+}
+
+// WithGeneratingTimeout applies the WithGeneratingTimeout option.
+func (h *HFSpace[I, O]) WithGeneratingTimeout(d time.Duration) *HFSpace[I, O] {
+	return h.apply(WithGeneratingTimeout(d))
+}
+
+// WithRetry applies the WithRetry option.
+func (h *HFSpace[I, O]) WithRetry(maxAttempts int, initialDelay time.Duration, multiplier float64) *HFSpace[I, O] {
+	return h.apply(WithRetry(maxAttempts, initialDelay, multiplier))
+}
+
+// WithRetryJitter applies the WithRetryJitter option.
+func (h *HFSpace[I, O]) WithRetryJitter(fraction float64) *HFSpace[I, O] {
+	return h.apply(WithRetryJitter(fraction))
+}
+
+// WithRetryCallback applies the WithRetryCallback option.
+func (h *HFSpace[I, O]) WithRetryCallback(fn func(attempt int, err error)) *HFSpace[I, O] {
+	return h.apply(WithRetryCallback(fn))
+}
+
+// WithUploader applies the WithUploader option.
+func (h *HFSpace[I, O]) WithUploader(u Uploader) *HFSpace[I, O] {
+	return h.apply(WithUploader(u))
+}
+
+// WithCircuitBreaker applies the WithCircuitBreaker option.
+func (h *HFSpace[I, O]) WithCircuitBreaker(threshold int, resetAfter time.Duration) *HFSpace[I, O] {
+	return h.apply(WithCircuitBreaker(threshold, resetAfter))
+}
+
+// WithPropagateAuthToDownloads applies the WithPropagateAuthToDownloads option.
+func (h *HFSpace[I, O]) WithPropagateAuthToDownloads() *HFSpace[I, O] {
+	return h.apply(WithPropagateAuthToDownloads())
+}
+
+// WithRequestID applies the WithRequestID option.
+func (h *HFSpace[I, O]) WithRequestID(gen func() string) *HFSpace[I, O] {
+	return h.apply(WithRequestID(gen))
+}
+
+// WithResponseSizeLimit applies the WithResponseSizeLimit option.
+func (h *HFSpace[I, O]) WithResponseSizeLimit(maxBytes int64) *HFSpace[I, O] {
+	return h.apply(WithResponseSizeLimit(maxBytes))
+}
+
+// WithKeepAlive applies the WithKeepAlive option.
+func (h *HFSpace[I, O]) WithKeepAlive(enable bool, idleTimeout time.Duration) *HFSpace[I, O] {
+	return h.apply(WithKeepAlive(enable, idleTimeout))
+}
+
+// WithHTTP2 applies the WithHTTP2 option.
+func (h *HFSpace[I, O]) WithHTTP2(enable bool) *HFSpace[I, O] {
+	return h.apply(WithHTTP2(enable))
+}
+
+// WithDebugDump applies the WithDebugDump option.
+func (h *HFSpace[I, O]) WithDebugDump(w io.Writer) *HFSpace[I, O] {
+	return h.apply(WithDebugDump(w))
+}
+
+// WithDebugDumpIncludeAuth applies the WithDebugDumpIncludeAuth option.
+func (h *HFSpace[I, O]) WithDebugDumpIncludeAuth(include bool) *HFSpace[I, O] {
+	return h.apply(WithDebugDumpIncludeAuth(include))
+}
+
+// WithEndpointBase applies the WithEndpointBase option.
+func (h *HFSpace[I, O]) WithEndpointBase(base string) *HFSpace[I, O] {
+	return h.apply(WithEndpointBase(base))
+}
+
+// WithEndpoints applies the WithEndpoints option.
+func (h *HFSpace[I, O]) WithEndpoints(m map[string]*EndpointConfig) *HFSpace[I, O] {
+	return h.apply(WithEndpoints(m))
+}
+
+// WithAutoWarmup applies the WithAutoWarmup option.
+func (h *HFSpace[I, O]) WithAutoWarmup() *HFSpace[I, O] {
+	return h.apply(WithAutoWarmup())
+}
+
+// WithMaxQueueDepth applies the WithMaxQueueDepth option.
+func (h *HFSpace[I, O]) WithMaxQueueDepth(n int) *HFSpace[I, O] {
+	return h.apply(WithMaxQueueDepth(n))
+}
+
+// WithSessionCookie applies the WithSessionCookie option.
+func (h *HFSpace[I, O]) WithSessionCookie(name, value string) *HFSpace[I, O] {
+	return h.apply(WithSessionCookie(name, value))
+}
+
+// WithDeduplication applies the WithDeduplication option.
+func (h *HFSpace[I, O]) WithDeduplication(ttl time.Duration) *HFSpace[I, O] {
+	return h.apply(WithDeduplication(ttl))
+}
+
+// WithDeduplicationMaxSize applies the WithDeduplicationMaxSize option.
+func (h *HFSpace[I, O]) WithDeduplicationMaxSize(n int) *HFSpace[I, O] {
+	return h.apply(WithDeduplicationMaxSize(n))
+}
+
+// WithTransportMiddleware applies the WithTransportMiddleware option.
+func (h *HFSpace[I, O]) WithTransportMiddleware(mw func(http.RoundTripper) http.RoundTripper) *HFSpace[I, O] {
+	return h.apply(WithTransportMiddleware(mw))
+}
+
+// WithGzip applies the WithGzip option.
+func (h *HFSpace[I, O]) WithGzip() *HFSpace[I, O] {
+	return h.apply(WithGzip())
+}
+
+// WithCookieJar applies the WithCookieJar option.
+func (h *HFSpace[I, O]) WithCookieJar(jar http.CookieJar) *HFSpace[I, O] {
+	return h.apply(WithCookieJar(jar))
+}
+
+// WithDefaultCookieJar applies the WithDefaultCookieJar option.
+func (h *HFSpace[I, O]) WithDefaultCookieJar() *HFSpace[I, O] {
+	return h.apply(WithDefaultCookieJar())
+}
+
+// WithFnIndex applies the WithFnIndex option.
+func (h *HFSpace[I, O]) WithFnIndex(index int) *HFSpace[I, O] {
+	return h.apply(WithFnIndex(index))
+}
+
+// WithDeduplicationWindow applies the WithDeduplicationWindow option.
+func (h *HFSpace[I, O]) WithDeduplicationWindow(d time.Duration) *HFSpace[I, O] {
+	return h.apply(WithDeduplicationWindow(d))
+}
+
+// WithLogger applies the WithLogger option.
+func (h *HFSpace[I, O]) WithLogger(l *slog.Logger) *HFSpace[I, O] {
+	return h.apply(WithLogger(l))
+}
+
+// WithTracer applies the WithTracer option.
+func (h *HFSpace[I, O]) WithTracer(t trace.Tracer) *HFSpace[I, O] {
+	return h.apply(WithTracer(t))
+}
+
+// WithMetrics applies the WithMetrics option.
+func (h *HFSpace[I, O]) WithMetrics(m MetricsRecorder) *HFSpace[I, O] {
+	return h.apply(WithMetrics(m))
+}
+
+// NewFileData is NewFileData() using the Uploader set with WithUploader, if any.
+// Its uploads are reported to the MetricsRecorder set with WithMetrics,
+// and hf:// files passed to its FromUrl() are downloaded with the space's Authorization header.
+func (h *HFSpace[I, O]) NewFileData(name string, mime ...string) *FileData {
+	u := h.uploader
+	if h.metrics != nil {
+		if u == nil {
+			u = getDefaultUploader()
+		}
+		u = meteredUploader{Uploader: u, metrics: h.metrics}
+	}
+	fd := NewFileData(name, mime...).WithUploader(u)
+	fd.hubAuth, _ = h.header("Authorization")
+	return fd
+}
+
+// WithErrorHandler gives fn a chance to recover whenever Do() would fail.
+// If fn returns a nil error, Do() returns fn's result instead of the original error.
+// It depends on the output type, so unlike most settings it is not available as an Option.
+func (h *HFSpace[I, O]) WithErrorHandler(fn func(err error) ([]O, error)) *HFSpace[I, O] {
+	h.errorHandler = fn
 	return h
 }
 
 // Do performs the full request + follow-up GET using the event ID.
 func (h *HFSpace[I, O]) Do(endpoint string, params ...I) ([]O, error) {
-	fullURL := fmt.Sprintf("%s/%s", h.BaseURL, strings.TrimLeft(endpoint, "/"))
+	return h.DoWithProgress(context.Background(), endpoint, nil, params...)
+}
+
+// DoWithContext is Do() with a context covering both the POST and the GET.
+// Cancellation or deadline expiry aborts the request in flight.
+func (h *HFSpace[I, O]) DoWithContext(ctx context.Context, endpoint string, params ...I) ([]O, error) {
+	return h.DoWithProgress(ctx, endpoint, nil, params...)
+}
+
+// DoWithProgress is DoWithContext() calling progress with the type and data of every event
+// the space sends before the final "complete" one, e.g. "generating" events with intermediate
+// outputs or "heartbeat" events. It is not called for the final event, nor for a request
+// deduplicated by WithDeduplicationWindow(), which observes the stream of the first caller.
+// See WithProgressCallback() for parsed gr.Progress steps.
+func (h *HFSpace[I, O]) DoWithProgress(ctx context.Context, endpoint string, progress func(event, data string), params ...I) ([]O, error) {
+	if progress != nil {
+		ctx = context.WithValue(ctx, eventObserverKey{}, progress)
+	}
+	res, err := h.call(ctx, endpoint, params)
+	if err != nil && h.errorHandler != nil {
+		return h.errorHandler(err)
+	}
+	return res, err
+}
+
+// DoOne is DoWithContext() for endpoints with a single output: it returns the first element
+// of the result, or an error wrapping ErrNoData if the result is empty.
+// Use Do() or DoWithContext() for endpoints with several outputs.
+func (h *HFSpace[I, O]) DoOne(ctx context.Context, endpoint string, params ...I) (O, error) {
+	var zero O
+	res, err := h.DoWithContext(ctx, endpoint, params...)
+	if err != nil {
+		return zero, err
+	}
+	if len(res) == 0 {
+		return zero, fmt.Errorf("hfs empty result: %w", ErrNoData)
+	}
+	return res[0], nil
+}
+
+// PredictEndpoint is the single endpoint of older Gradio demos, named after their predict function.
+const PredictEndpoint = "predict"
+
+// Predict is DoWithContext() on PredictEndpoint, for demos exposing a single "predict" endpoint.
+func (h *HFSpace[I, O]) Predict(ctx context.Context, params ...I) ([]O, error) {
+	return h.DoWithContext(ctx, PredictEndpoint, params...)
+}
+
+// PredictOne is DoOne() on PredictEndpoint.
+func (h *HFSpace[I, O]) PredictOne(ctx context.Context, params ...I) (O, error) {
+	return h.DoOne(ctx, PredictEndpoint, params...)
+}
+
+// DoFile is DoOne() for spaces returning a single file: it downloads the FileData output
+// and saves it to outputPath like FileData.SaveTo(), or writes it to stdout if outputPath is "-".
+func (h *HFSpace[I, O]) DoFile(ctx context.Context, endpoint, outputPath string, params ...I) error {
+	out, err := h.DoOne(ctx, endpoint, params...)
+	if err != nil {
+		return err
+	}
+	fd, err := toFileData(out)
+	if err != nil {
+		return err
+	}
+	h.authorizeDownload(&fd)
+
+	if outputPath != "-" {
+		return fd.SaveTo(ctx, outputPath)
+	}
+	content, err := fileDataDownload(ctx, &fd, 0, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stdout.Write(content); err != nil {
+		return fmt.Errorf("hfs filedata write stdout: %w", err)
+	}
+	return nil
+}
+
+// DoRaw sends body verbatim as the POST body of endpoint and returns the data of the final event
+// as is, without decoding it. It is meant for payloads the typed API cannot express, e.g.
+// pre-marshaled state objects, and for debugging. body is usually {"data": [...]}.
+// The other settings, such as retries and deduplication, apply as for Do().
+func (h *HFSpace[I, O]) DoRaw(ctx context.Context, endpoint string, body []byte) (_ []byte, err error) {
+	if h.metrics != nil {
+		defer func(start time.Time) { h.observeRequest(endpoint, start, err) }(time.Now())
+	}
+	return h.doBody(ctx, h.endpointURL(endpoint), body)
+}
+
+// DoNamed is DoWithContext() for spaces whose inputs are addressed by name:
+// params is sent as a JSON object, {"data": {"prompt": ..., "seed": ...}},
+// where Do() sends the positional array {"data": [...]}.
+// Spaces that only accept positional inputs reject it with an error event or HTTP status.
+func (h *HFSpace[I, O]) DoNamed(ctx context.Context, endpoint string, params map[string]any) ([]O, error) {
+	res, err := h.doNamed(ctx, endpoint, params)
+	if err != nil && h.errorHandler != nil {
+		return h.errorHandler(err)
+	}
+	return res, err
+}
+
+func (h *HFSpace[I, O]) doNamed(ctx context.Context, endpoint string, params map[string]any) ([]O, error) {
+	if err := validateParams([]map[string]any{params}); err != nil {
+		return nil, err
+	}
+	data, err := h.callData(ctx, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+	return h.decodeResult(data)
+}
+
+// DoByIndex is DoWithContext() for older Gradio apps that expose functions by index instead of by name.
+// It POSTs {"fn_index": fnIndex, "data": [...]} to BaseURL itself, without an endpoint path segment.
+func (h *HFSpace[I, O]) DoByIndex(ctx context.Context, fnIndex int, params ...I) ([]O, error) {
+	res, err := h.doByIndex(ctx, fnIndex, params)
+	if err != nil && h.errorHandler != nil {
+		return h.errorHandler(err)
+	}
+	return res, err
+}
+
+func (h *HFSpace[I, O]) doByIndex(ctx context.Context, fnIndex int, params []I) ([]O, error) {
+	if err := validateParams(params); err != nil {
+		return nil, err
+	}
+	payload := map[string]any{"fn_index": fnIndex, "data": params}
+	data, err := h.callPayload(ctx, h.BaseURL, fmt.Sprintf("fn_index=%d", fnIndex), payload)
+	if err != nil {
+		return nil, err
+	}
+	return h.decodeResult(data)
+}
+
+// Submit performs only the POST step and returns the event ID without waiting for the result.
+// Use FetchResult() later with the same endpoint to collect it.
+func (h *HFSpace[I, O]) Submit(ctx context.Context, endpoint string, params ...I) (string, error) {
+	body, err := h.marshalParams(params)
+	if err != nil {
+		return "", err
+	}
+	fullURL := h.endpointURL(endpoint)
+	ctx, _ = h.withRequestID(ctx)
+	eventID, err := h.post(ctx, fullURL, body)
+	if err != nil {
+		return "", err
+	}
+	h.pending.Store(eventID, fullURL)
+	return eventID, nil
+}
+
+// FetchResult performs only the GET step for an event ID returned by Submit().
+func (h *HFSpace[I, O]) FetchResult(ctx context.Context, endpoint, eventID string) ([]O, error) {
+	if h.schemaErr != nil {
+		return nil, h.schemaErr
+	}
+	data, err := h.fetch(ctx, h.endpointURL(endpoint), eventID)
+	if ctx.Err() == nil {
+		h.pending.Delete(eventID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h.decodeResult(data)
+}
+
+func (h *HFSpace[I, O]) endpointURL(endpoint string) string {
+	return fmt.Sprintf("%s/%s", h.BaseURL, strings.TrimLeft(endpoint, "/"))
+}
+
+// endpointOf returns the endpoint part of a URL built by endpointURL.
+func (h *HFSpace[I, O]) endpointOf(fullURL string) string {
+	return strings.TrimPrefix(fullURL, h.BaseURL)
+}
+
+func (h *HFSpace[I, O]) marshalParams(params []I) ([]byte, error) {
+	if err := validateParams(params); err != nil {
+		return nil, err
+	}
+	return marshalPayload(h.dataPayload(params))
+}
+
+// dataPayload builds the request body {"data": data}, plus the function index set by WithFnIndex.
+func (c *config) dataPayload(data any) map[string]any {
+	payload := map[string]any{"data": data}
+	if c.fnIndex >= 0 {
+		payload["fn_index"] = c.fnIndex
+	}
+	return payload
+}
+
+func marshalPayload(payload map[string]any) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("hfs req body marshall: %w", err)
+	}
+	return body, nil
+}
+
+// call runs the request for endpoint and decodes the final result as []O.
+func (h *HFSpace[I, O]) call(ctx context.Context, endpoint string, params []I) ([]O, error) {
+	data, err := h.callRaw(ctx, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+	return h.decodeResult(data)
+}
+
+// callRaw marshals params for endpoint and runs the request, deduplicating if configured.
+// Returns the JSON payload of the final event.
+func (h *HFSpace[I, O]) callRaw(ctx context.Context, endpoint string, params []I) ([]byte, error) {
+	if err := validateParams(params); err != nil {
+		return nil, err
+	}
+	return h.callData(ctx, endpoint, params)
+}
+
+// callData is callRaw() for any "data" payload, e.g. the map of DoNamed().
+func (h *HFSpace[I, O]) callData(ctx context.Context, endpoint string, data any) ([]byte, error) {
+	return h.callPayload(ctx, h.endpointURL(endpoint), endpoint, h.dataPayload(data))
+}
+
+// callPayload sends payload as the request body to fullURL. endpoint labels the request in metrics.
+func (h *HFSpace[I, O]) callPayload(ctx context.Context, fullURL, endpoint string, payload map[string]any) (_ []byte, err error) {
+	if h.metrics != nil {
+		defer func(start time.Time) { h.observeRequest(endpoint, start, err) }(time.Now())
+	}
+	if h.schemaErr != nil {
+		return nil, h.schemaErr
+	}
+	body, err := marshalPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	return h.doBody(ctx, fullURL, body)
+}
+
+// doBody sends the marshaled body to fullURL, deduplicating if configured.
+func (h *HFSpace[I, O]) doBody(ctx context.Context, fullURL string, body []byte) ([]byte, error) {
+	if h.dedupWindow <= 0 {
+		return h.do(ctx, fullURL, body)
+	}
+	return h.doDedup(ctx, fullURL, body)
+}
+
+// doDedup runs do() at most once per distinct request within the deduplication window.
+// Callers waiting for the first one give up when their own ctx is done, and run do() themselves
+// if the first one failed because of its context, as theirs may still be live.
+func (h *HFSpace[I, O]) doDedup(ctx context.Context, fullURL string, body []byte) ([]byte, error) {
+	key := sha256.Sum256(append([]byte(fullURL+"\n"), body...))
+	entry := &dedupEntry{key: key, done: make(chan struct{})}
+
+	if prev, loaded := h.dedup.LoadOrStore(key, entry); loaded {
+		first := prev.(*dedupEntry)
+		select {
+		case <-first.done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("hfs dedup wait: %w", ctx.Err())
+		}
+		if errors.Is(first.err, context.Canceled) || errors.Is(first.err, context.DeadlineExceeded) {
+			return h.do(ctx, fullURL, body)
+		}
+		h.dedupLRU.touch(first)
+		return first.data, first.err
+	}
 
-	// Step 1: POST request
-	payload := map[string]any{
-		"data": params,
+	entry.data, entry.err = h.do(ctx, fullURL, body)
+	close(entry.done)
+
+	// Failed requests are only shared with callers that were already waiting.
+	if entry.err != nil {
+		h.dedup.CompareAndDelete(key, entry)
+		return entry.data, entry.err
+	}
+	for _, old := range h.dedupLRU.add(entry, h.dedupMaxSize) {
+		h.dedup.CompareAndDelete(old.key, old)
+	}
+	time.AfterFunc(h.dedupWindow, func() {
+		h.dedup.CompareAndDelete(key, entry)
+		h.dedupLRU.remove(entry)
+	})
+	return entry.data, entry.err
+}
+
+// do runs roundTrip, retrying transient failures if configured, unless the circuit breaker is open.
+func (h *HFSpace[I, O]) do(ctx context.Context, fullURL string, body []byte) (_ []byte, err error) {
+	if err := h.checkQueueDepth(ctx); err != nil {
+		return nil, err
+	}
+	if h.breaker != nil {
+		if !h.breaker.allow() {
+			return nil, ErrCircuitOpen
+		}
+		defer func() { h.breaker.record(err) }()
+	}
+	first := h.autoWarmup && h.warmedUp.CompareAndSwap(false, true)
+	data, err := h.retryRoundTrip(ctx, fullURL, body)
+	if first && isSleeping(err) {
+		if werr := h.warmup(ctx, fullURL); werr != nil {
+			return nil, err
+		}
+		return h.retryRoundTrip(ctx, fullURL, body)
+	}
+	return data, err
+}
+
+// retryRoundTrip runs roundTrip, retrying transient failures if configured.
+func (h *HFSpace[I, O]) retryRoundTrip(ctx context.Context, fullURL string, body []byte) ([]byte, error) {
+	delay := h.retry.initialDelay
+	for attempt := 1; ; attempt++ {
+		data, err := h.roundTrip(ctx, fullURL, body)
+		if err == nil || attempt >= h.retry.maxAttempts || !isRetryable(err) {
+			return data, err
+		}
+		if h.retry.callback != nil {
+			h.retry.callback(attempt, err)
+		}
+
+		wait := delay
+		if h.retry.jitter > 0 {
+			wait = time.Duration(float64(wait) * (1 + h.retry.jitter*(2*mrand.Float64()-1)))
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("hfs retry wait: %w", ctx.Err())
+		}
+		delay = time.Duration(float64(delay) * h.retry.multiplier)
+	}
+}
+
+// isRetryable reports whether err is a temporary network error or a 429/503 reply.
+func isRetryable(err error) bool {
+	var herr *HTTPStatusError
+	if errors.As(err, &herr) {
+		return herr.StatusCode == http.StatusTooManyRequests || herr.StatusCode == http.StatusServiceUnavailable
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr) && nerr.Temporary()
+}
+
+// roundTrip sends the marshaled body to fullURL and waits for the payload of the final event.
+func (h *HFSpace[I, O]) roundTrip(ctx context.Context, fullURL string, body []byte) ([]byte, error) {
+	ctx, requestID := h.withRequestID(ctx)
+	data, err := h.roundTripEvent(ctx, fullURL, body)
+	if err != nil && requestID != "" {
+		return nil, fmt.Errorf("hfs request %s: %w", requestID, err)
+	}
+	return data, err
+}
+
+func (h *HFSpace[I, O]) roundTripEvent(ctx context.Context, fullURL string, body []byte) ([]byte, error) {
+	if h.syncAPI() {
+		return h.postSync(ctx, fullURL, body)
+	}
+	if h.httpCache != nil {
+		return h.doCached(ctx, fullURL, body)
+	}
+	if h.clientIDs {
+		return h.doClientID(ctx, fullURL, body)
+	}
+	eventID, err := h.post(ctx, fullURL, body)
+	if err != nil {
+		return nil, err
+	}
+	return h.fetch(ctx, fullURL, eventID)
+}
+
+// doCached replays a cached event stream for the request if there is one,
+// otherwise runs the request and caches its event stream on success.
+func (h *HFSpace[I, O]) doCached(ctx context.Context, fullURL string, body []byte) ([]byte, error) {
+	cacheReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("hfs post req create: %w", err)
+	}
+	h.applyHeaders(cacheReq.Header)
+
+	if cached, ok := h.httpCache.Get(cacheReq); ok {
+		defer cached.Body.Close()
+		return h.readData(ctx, cached.Body, "")
+	}
+
+	eventID, err := h.post(ctx, fullURL, body)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := h.stream(ctx, fullURL, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var raw bytes.Buffer
+	data, err := h.readData(ctx, struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(stream, &raw), stream}, eventID)
+	if err != nil {
+		return nil, err
+	}
+	h.httpCache.Set(cacheReq, &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/event-stream"}},
+		Body:          io.NopCloser(&raw),
+		ContentLength: int64(raw.Len()),
+		Request:       cacheReq,
+	})
+	return data, nil
+}
+
+// doClientID runs the POST and the GET concurrently using a client-generated event ID.
+func (h *HFSpace[I, O]) doClientID(ctx context.Context, fullURL string, body []byte) ([]byte, error) {
+	eventID, err := newEventID()
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("hfs req body event ID inject: %w", err)
+	}
+	payload["event_id"], _ = json.Marshal(eventID)
+	if body, err = json.Marshal(payload); err != nil {
+		return nil, fmt.Errorf("hfs req body marshall: %w", err)
+	}
+
+	getCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type postResult struct {
+		eventID string
+		err     error
+	}
+	posted := make(chan postResult, 1)
+	go func() {
+		id, err := h.post(ctx, fullURL, body)
+		if err != nil {
+			cancel()
+		}
+		posted <- postResult{id, err}
+	}()
+
+	data, fetchErr := h.fetch(getCtx, fullURL, eventID)
+	p := <-posted
+	if p.err != nil {
+		return nil, p.err
+	}
+	if fetchErr == nil {
+		return data, nil
+	}
+	// The GET raced ahead of the POST or the server ignored our ID: retry with the server's.
+	return h.fetch(ctx, fullURL, p.eventID)
+}
+
+// newEventID returns a random UUID v4 in the 32 hex digit form Gradio uses.
+func newEventID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("hfs event ID generate: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return hex.EncodeToString(b[:]), nil
+}
+
+// send applies the request transforms and executes req.
+func (h *HFSpace[I, O]) send(req *http.Request) (*http.Response, error) {
+	if h.optErr != nil {
+		return nil, h.optErr
+	}
+	setRequestIDHeader(req)
+	if h.gzip {
+		var err error
+		if req, err = gzipRequest(req); err != nil {
+			return nil, err
+		}
+	}
+	for _, fn := range h.transforms {
+		var err error
+		if req, err = fn(req); err != nil {
+			return nil, fmt.Errorf("hfs req transform: %w", err)
+		}
+	}
+	client := h.client
+	if h.dump != nil {
+		client = h.dumpClient()
+	}
+	resp, err := client.Do(req)
+	if err == nil && h.gzip {
+		gunzipResponse(resp)
 	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("hfs req body marshall: %w", err)
+	return resp, err
+}
+
+// post is step 1: send the request body and decode the event ID.
+func (h *HFSpace[I, O]) post(ctx context.Context, fullURL string, body []byte) (eventID string, err error) {
+	status := 0
+	if h.limiter != nil {
+		if err := h.limiter.Wait(ctx); err != nil {
+			return "", fmt.Errorf("hfs rate limit wait: %w", err)
+		}
 	}
+	ctx, p := h.startPhase(ctx, "post", fullURL, h.endpointOf(fullURL))
+	defer func() { p.end(status, eventID, err) }()
 
-	req, err := http.NewRequest("POST", fullURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, fmt.Errorf("hfs post req create: %w", err)
-	}
-	for k, v := range h.Headers {
-		req.Header.Set(k, v)
+		return "", fmt.Errorf("hfs post req create: %w", err)
 	}
+	h.applyHeaders(req.Header)
 
-	resp, err := h.client.Do(req)
+	resp, err := h.send(req)
 	if err != nil {
-		return nil, fmt.Errorf("hfs post req exec: %w", err)
+		return "", fmt.Errorf("hfs post req exec: %w", err)
 	}
 	defer resp.Body.Close()
+	status = resp.StatusCode
+	if err := checkStatus(resp); err != nil {
+		return "", fmt.Errorf("hfs post resp: %w", err)
+	}
 
 	// Decode event ID
-	var idResp struct {
-		Eventid string `json:"event_id"`
+	var idResp map[string]json.RawMessage
+	if err := json.NewDecoder(h.limitBody(resp.Body)).Decode(&idResp); err != nil {
+		if errors.Is(err, ErrResponseTooLarge) {
+			return "", fmt.Errorf("hfs post resp read: %w", err)
+		}
+		return "", withKind(ErrDecodeFailure, fmt.Errorf("hfs event ID decode: %w", err))
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&idResp); err != nil {
-		return nil, fmt.Errorf("hfs event ID decode: %w", err)
+	if err := json.Unmarshal(idResp[h.eventIDField], &eventID); err != nil {
+		return "", withKind(ErrDecodeFailure, fmt.Errorf("hfs event ID field %q decode: %w", h.eventIDField, err))
+	}
+	if h.connReuse {
+		// A body closed before EOF takes its connection down with it.
+		// Drain it so the GET can pick the connection up from the idle pool.
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	}
+	return eventID, nil
+}
+
+// fetch is step 2: read the event stream for eventID up to the payload of the final event.
+func (h *HFSpace[I, O]) fetch(ctx context.Context, fullURL, eventID string) ([]byte, error) {
+	stream, err := h.stream(ctx, fullURL, eventID)
+	if err != nil {
+		return nil, err
 	}
-	eventID := idResp.Eventid
+	defer stream.Close()
+	return h.readData(ctx, stream, eventID)
+}
 
+// stream opens the event stream for eventID.
+func (h *HFSpace[I, O]) stream(ctx context.Context, fullURL, eventID string) (_ io.ReadCloser, err error) {
 	// Step 2: GET request to fetch final result
 	streamURL := fmt.Sprintf("%s/%s", fullURL, eventID)
+	status := 0
+	ctx, p := h.startPhase(ctx, "get", streamURL, h.endpointOf(fullURL))
+	defer func() { p.end(status, eventID, err) }()
 
-	getReq, err := http.NewRequest("GET", streamURL, nil)
+	getReq, err := http.NewRequestWithContext(ctx, "GET", streamURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("hfs get req create: %w", err)
 	}
-	for k, v := range h.Headers {
-		getReq.Header.Set(k, v)
-	}
+	h.applyHeaders(getReq.Header)
 
-	resp2, err := h.client.Do(getReq)
+	resp2, err := h.send(getReq)
 	if err != nil {
 		return nil, fmt.Errorf("hfs get req exec: %w", err)
 	}
-	defer resp2.Body.Close()
-
-	res2, err := io.ReadAll(resp2.Body)
-	if err != nil {
-		return nil, fmt.Errorf("hfs get resp read: %w", err)
+	status = resp2.StatusCode
+	if err := checkStatus(resp2); err != nil {
+		resp2.Body.Close()
+		return nil, fmt.Errorf("hfs get resp: %w", err)
 	}
+	return h.limitBody(resp2.Body), nil
+}
 
-	lines := strings.Split(string(res2), "\n")
+// eventObserverKey is the context key of the progress callback of DoWithProgress.
+type eventObserverKey struct{}
 
-	EventCompleted := false
-	var data string
-	for _, line := range lines {
-		if strings.HasPrefix(line, "event:") {
-			if strings.Contains(line, "error") {
-				return nil, fmt.Errorf("hfs event error")
+// readData reads an event stream up to the complete event and returns its data payload.
+// stream is closed early if the generating timeout fires.
+func (h *HFSpace[I, O]) readData(ctx context.Context, stream io.ReadCloser, eventID string) (_ []byte, err error) {
+	_, p := h.startPhase(ctx, "parse", "", "")
+	defer func() { p.end(0, eventID, err) }()
+
+	var stalled atomic.Bool
+	var watchdog *time.Timer
+	if h.generatingTimeout > 0 {
+		defer func() {
+			if watchdog != nil {
+				watchdog.Stop()
 			}
-			if strings.Contains(line, "complete") {
-				EventCompleted = true
+		}()
+	}
+
+	observe, _ := ctx.Value(eventObserverKey{}).(func(event, data string))
+	events := newSSEScanner(stream)
+	var data string
+	for events.Scan() {
+		ev := events.Event()
+		if ev.Type == "error" {
+			return nil, newEventError(eventID, ev.Data)
+		}
+		if h.generatingTimeout > 0 && ev.Type == "generating" {
+			if watchdog == nil {
+				watchdog = time.AfterFunc(h.generatingTimeout, func() {
+					stalled.Store(true)
+					stream.Close()
+				})
+			} else {
+				watchdog.Reset(h.generatingTimeout)
 			}
 		}
-		if strings.HasPrefix(line, "data:") {
-			data = strings.TrimSpace(line[len("data:"):])
-			if EventCompleted {
-				break
+		if observe != nil && ev.Type != "complete" {
+			observe(ev.Type, ev.Data)
+		}
+		if ev.Data == "" {
+			continue
+		}
+		data = ev.Data
+		if ev.Type == "complete" {
+			break
+		}
+		if h.onProgress != nil {
+			for _, p := range parseProgress(data) {
+				h.onProgress(p)
 			}
 		}
 	}
+	if err := events.Err(); err != nil {
+		if stalled.Load() {
+			return nil, ErrGeneratingTimeout
+		}
+		return nil, fmt.Errorf("hfs get resp read: %w", err)
+	}
 
 	if len(data) == 0 {
-		return nil, fmt.Errorf("hfs no data in resp")
+		return nil, ErrNoData
 	}
+	return []byte(data), nil
+}
 
+// decodeResult decodes the payload of the final event as []O.
+func (h *HFSpace[I, O]) decodeResult(data []byte) ([]O, error) {
 	// Final result
 	var Result []O
-	if err := json.Unmarshal([]byte(data), &Result); err != nil {
-		return nil, fmt.Errorf("hfs decode final resp: %w", err)
+	if err := json.Unmarshal(data, &Result); err != nil {
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("hfs decode final resp: %w", err))
+	}
+	if h.outputSchema != nil {
+		if err := h.outputSchema.validate(data); err != nil {
+			return nil, err
+		}
+	}
+	if h.propagateAuth {
+		for i := range Result {
+			h.authorizeOutput(&Result[i])
+		}
 	}
 
 	return Result, nil
 }
 
+// ProgressEvent is one step-level progress update emitted by a Gradio space (gr.Progress).
+type ProgressEvent struct {
+	Current int    // completed steps, e.g. 3 in "3/10 steps"
+	Total   int    // total steps, 0 if unknown
+	Desc    string // description set by the space, e.g. "Generating"
+	Unit    string // step unit, e.g. "steps"
+}
+
+func (p ProgressEvent) String() string {
+	s := fmt.Sprintf("%d", p.Current)
+	if p.Total > 0 {
+		s += fmt.Sprintf("/%d", p.Total)
+	}
+	if p.Unit != "" {
+		s += " " + p.Unit
+	}
+	if p.Desc != "" {
+		s = p.Desc + ": " + s
+	}
+	return s
+}
+
+// parseProgress extracts progress updates from an SSE data payload.
+// Returns nil if the payload is not a Gradio progress message.
+func parseProgress(data string) []ProgressEvent {
+	if !strings.HasPrefix(data, "{") {
+		return nil
+	}
+	var msg struct {
+		Msg          string `json:"msg"`
+		ProgressData []struct {
+			Index  *int   `json:"index"`
+			Value  *int   `json:"value"`
+			Length *int   `json:"length"`
+			Desc   string `json:"desc"`
+			Unit   string `json:"unit"`
+		} `json:"progress_data"`
+	}
+	if err := json.Unmarshal([]byte(data), &msg); err != nil || msg.Msg != "progress" {
+		return nil
+	}
+
+	events := make([]ProgressEvent, 0, len(msg.ProgressData))
+	for _, pd := range msg.ProgressData {
+		p := ProgressEvent{Desc: pd.Desc, Unit: pd.Unit}
+		switch {
+		case pd.Value != nil:
+			p.Current = *pd.Value
+		case pd.Index != nil:
+			p.Current = *pd.Index
+		}
+		if pd.Length != nil {
+			p.Total = *pd.Length
+		}
+		events = append(events, p)
+	}
+	return events
+}
+
 // Gradio-compatible FileData structure.
 // Usually used for images, audio, video, etc.
 type FileData struct {
@@ -163,51 +1130,415 @@ type FileData struct {
 	MimeType *string        `json:"mime_type"`
 	IsStream bool           `json:"is_stream"`
 	Meta     map[string]any `json:"meta,omitempty"`
+
+	encoding    *base64.Encoding
+	authHeaders map[string]string
+	hubAuth     string // Authorization header for hf:// files
+	upl         Uploader
+	uploadedBy  Uploader // the Uploader that stored the content, for Delete()
+	maxBytes    int64
+	progress    ProgressFunc
 }
 
-func NewFileData(name string) *FileData {
-	return &FileData{
+// DefaultUploadMaxBytes is the Quax upload limit used by FileData.FromFile.
+const DefaultUploadMaxBytes = 200 << 20
+
+// Base64 variants accepted by FileData.WithBase64Encoding.
+var (
+	StdBase64    = base64.StdEncoding
+	URLBase64    = base64.URLEncoding
+	RawStdBase64 = base64.RawStdEncoding
+	RawURLBase64 = base64.RawURLEncoding
+)
+
+// NewFileData creates a FileData named name. An optional mime type can be given upfront,
+// otherwise it is detected from the content when uploading.
+func NewFileData(name string, mime ...string) *FileData {
+	fd := &FileData{
 		OrigName: name,
 		IsStream: false,
 		MimeType: nil,
 		Meta:     map[string]any{"_type": "gradio.FileData"},
 	}
+	if len(mime) > 0 && mime[0] != "" {
+		fd.MimeType = &mime[0]
+	}
+	return fd
+}
+
+// Clone returns a copy of fd that can be modified, e.g. by another goroutine, without affecting fd.
+// Meta and the auth headers are copied; the values in Meta are shared.
+func (fd *FileData) Clone() *FileData {
+	c := *fd
+	c.Meta = maps.Clone(fd.Meta)
+	c.authHeaders = maps.Clone(fd.authHeaders)
+	if fd.MimeType != nil {
+		mime := *fd.MimeType
+		c.MimeType = &mime
+	}
+	return &c
 }
 
+// WithMimeType sets the mime type instead of detecting it from the content.
+func (fd *FileData) WithMimeType(mime string) *FileData {
+	fd.MimeType = &mime
+	return fd
+}
+
+// detectMimeType sets MimeType from the first bytes of the content unless already set.
+func (fd *FileData) detectMimeType(head []byte) {
+	if fd.MimeType != nil {
+		return
+	}
+	mime := http.DetectContentType(head)
+	fd.MimeType = &mime
+}
+
+// FromUrl points fd at the file at url, which the space downloads itself.
+// Files on the Hugging Face Hub can be given as "hf://owner/repo/path", optionally with a revision
+// as in "hf://owner/repo@revision/path", or as "hf://datasets/owner/repo/path" for datasets.
+// Their downloads by this package, e.g. SaveTo(), carry the token set with WithHubToken();
+// the space itself fetches the file without it, so only public Hub files work as inputs.
 func (fd *FileData) FromUrl(url string) (*FileData, error) {
+	if strings.HasPrefix(url, "hf://") {
+		resolved, err := resolveHubURL(url)
+		if err != nil {
+			return nil, withKind(ErrInvalidFileData, err)
+		}
+		url = resolved
+		if fd.hubAuth != "" {
+			fd.WithAuthHeader("Authorization", fd.hubAuth)
+		}
+	}
 	fd.URL = url
 	fd.Path = url
 	fd.Size = 0
+	if err := fd.Validate(); err != nil {
+		return nil, err
+	}
 	return fd, nil
 }
 
-func (fd *FileData) FromBytes(data []byte) (*FileData, error) {
+// FromBytes uploads data through the Uploader of fd. With opts, it is uploaded to Quax
+// configured by them instead, e.g. FromBytes(data, hfs.QuaxWithUserhash(hash)).
+func (fd *FileData) FromBytes(data []byte, opts ...QuaxOption) (*FileData, error) {
 	if len(data) == 0 {
 		return nil, fmt.Errorf("hfs empty data")
 	}
 
-	url, err := NewQuax(nil).rawUpload(data, fd.OrigName)
+	var url string
+	var err error
+	u := fd.uploaderWith(opts)
+	if fd.progress == nil {
+		url, err = u.Upload(context.Background(), data, fd.OrigName)
+	} else {
+		r := newProgressReader(bytes.NewReader(data), int64(len(data)), fd.progress)
+		url, err = uploadReader(context.Background(), u, r, int64(len(data)), fd.OrigName)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("hfs quax upload: %w", err)
+		return nil, withKind(ErrUploadFailure, fmt.Errorf("hfs upload: %w", err))
 	}
 
+	fd.uploadedBy = u
 	fd.URL = url
 	fd.Path = url
 	fd.Size = int64(len(data))
+	fd.detectMimeType(data[:min(len(data), 512)])
+	if err := fd.Validate(); err != nil {
+		return nil, err
+	}
+	return fd, nil
+}
+
+// FromReader uploads r, streaming it without loading it into memory if the uploader supports it (Quax does).
+// Size is only set if r is also an io.Seeker.
+func (fd *FileData) FromReader(ctx context.Context, r io.Reader, name string) (*FileData, error) {
+	size := int64(-1)
+	if seeker, ok := r.(io.Seeker); ok {
+		cur, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("hfs reader seek: %w", err)
+		}
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("hfs reader seek: %w", err)
+		}
+		if _, err := seeker.Seek(cur, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("hfs reader seek: %w", err)
+		}
+		size = end - cur
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("hfs read: %w", err)
+	}
+	head = head[:n]
+
+	body := newProgressReader(io.MultiReader(bytes.NewReader(head), r), size, fd.progress)
+	u := fd.uploader()
+	url, err := uploadReader(ctx, u, body, size, name)
+	if err != nil {
+		return nil, withKind(ErrUploadFailure, fmt.Errorf("hfs upload: %w", err))
+	}
+
+	fd.uploadedBy = u
+	fd.URL = url
+	fd.Path = url
+	fd.Size = max(size, 0)
+	if fd.OrigName == "" {
+		fd.OrigName = name
+	}
+	fd.detectMimeType(head)
+	if err := fd.Validate(); err != nil {
+		return nil, err
+	}
+	return fd, nil
+}
+
+// FromHTTPResponse uploads the body of resp, e.g. a download from another API, streaming it like
+// FromReader, and closes it. OrigName is taken from the Content-Disposition filename if present,
+// MimeType from Content-Type and Size from Content-Length. Non-2xx responses are rejected.
+func (fd *FileData) FromHTTPResponse(resp *http.Response) (*FileData, error) {
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return nil, fmt.Errorf("hfs filedata from response: %w", err)
+	}
+
+	name := "file"
+	if resp.Request != nil && resp.Request.URL != nil {
+		if base := path.Base(resp.Request.URL.Path); base != "/" && base != "." {
+			name = base
+		}
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		name = filepath.Base(params["filename"])
+		fd.OrigName = name
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		fd.WithMimeType(mediaType)
+	}
+
+	ctx := context.Background()
+	if resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+	if _, err := fd.FromReader(ctx, resp.Body, name); err != nil {
+		return nil, err
+	}
+	if resp.ContentLength >= 0 {
+		fd.Size = resp.ContentLength
+	}
+	return fd, nil
+}
+
+// FromFile uploads the file at path, streaming it like FromReader.
+// Files larger than the upload limit (see WithUploadMaxBytes) are rejected before uploading.
+// With opts, the file is uploaded to Quax configured by them, like FromBytes.
+func (fd *FileData) FromFile(ctx context.Context, path string, opts ...QuaxOption) (*FileData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("hfs open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("hfs stat file: %w", err)
+	}
+	limit := fd.maxBytes
+	if limit <= 0 {
+		limit = DefaultUploadMaxBytes
+	}
+	if info.Size() > limit {
+		return nil, fmt.Errorf("hfs file %s is %d bytes, over the %d byte upload limit", path, info.Size(), limit)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("hfs read file: %w", err)
+	}
+	head = head[:n]
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("hfs seek file: %w", err)
+	}
+
+	name := filepath.Base(path)
+	body := newProgressReader(file, info.Size(), fd.progress)
+	u := fd.uploaderWith(opts)
+	url, err := uploadReader(ctx, u, body, info.Size(), name)
+	if err != nil {
+		return nil, withKind(ErrUploadFailure, fmt.Errorf("hfs upload: %w", err))
+	}
+
+	fd.uploadedBy = u
+	fd.URL = url
+	fd.Path = url
+	fd.Size = info.Size()
+	fd.OrigName = name
+	fd.detectMimeType(head)
+	if err := fd.Validate(); err != nil {
+		return nil, err
+	}
 	return fd, nil
 }
 
+// WithProgress reports upload progress of FromBytes, FromReader and FromFile to f.
+func (fd *FileData) WithProgress(f ProgressFunc) *FileData {
+	fd.progress = f
+	return fd
+}
+
+// WithUploadMaxBytes sets the size limit enforced by FromFile. Defaults to DefaultUploadMaxBytes.
+func (fd *FileData) WithUploadMaxBytes(n int64) *FileData {
+	fd.maxBytes = n
+	return fd
+}
+
+// WithUploader sets the Uploader used by FromBytes, FromBase64, FromReader and FromFile.
+// Defaults to the one set with SetDefaultUploader, or Quax.
+func (fd *FileData) WithUploader(u Uploader) *FileData {
+	fd.upl = u
+	return fd
+}
+
+func (fd *FileData) uploader() Uploader {
+	if fd.upl == nil {
+		return getDefaultUploader()
+	}
+	return fd.upl
+}
+
+// uploaderWith returns a Quax configured by opts if any, otherwise fd.uploader().
+func (fd *FileData) uploaderWith(opts []QuaxOption) Uploader {
+	if len(opts) > 0 {
+		return NewQuax(opts...).AsUploader()
+	}
+	return fd.uploader()
+}
+
+// Validate checks that fd can be sent to a space: it needs a parseable URL or Path,
+// a non-negative Size, a type/subtype MimeType if set, and Meta["_type"] == "gradio.FileData".
+// All violations are returned together, wrapped in ErrInvalidFileData.
+func (fd *FileData) Validate() error {
+	var errs []error
+	if fd.URL == "" && fd.Path == "" {
+		errs = append(errs, errors.New("url and path are both empty"))
+	}
+	if fd.URL != "" {
+		if _, err := url.Parse(fd.URL); err != nil {
+			errs = append(errs, fmt.Errorf("url: %w", err))
+		}
+	}
+	if fd.Path != "" {
+		if _, err := url.Parse(fd.Path); err != nil {
+			errs = append(errs, fmt.Errorf("path: %w", err))
+		}
+	}
+	if fd.Size < 0 {
+		errs = append(errs, fmt.Errorf("size is negative: %d", fd.Size))
+	}
+	if fd.MimeType != nil {
+		mediaType, _, err := mime.ParseMediaType(*fd.MimeType)
+		if typ, sub, ok := strings.Cut(mediaType, "/"); err != nil || !ok || typ == "" || sub == "" || strings.Contains(sub, "/") {
+			errs = append(errs, fmt.Errorf("mime type %q is not type/subtype", *fd.MimeType))
+		}
+	}
+	if t, _ := fd.Meta["_type"].(string); t != "gradio.FileData" {
+		errs = append(errs, fmt.Errorf(`meta _type is %v, want "gradio.FileData"`, fd.Meta["_type"]))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return withKind(ErrInvalidFileData, fmt.Errorf("hfs invalid FileData: %w", errors.Join(errs...)))
+}
+
+// WithAuthHeader sets a header sent only when downloading this file,
+// e.g. for pre-signed URLs that need different auth than the space.
+func (fd *FileData) WithAuthHeader(key, value string) *FileData {
+	if fd.authHeaders == nil {
+		fd.authHeaders = map[string]string{}
+	}
+	fd.authHeaders[key] = value
+	return fd
+}
+
+// WithHubToken sets the Hugging Face token sent when downloading an hf:// file given to FromUrl().
+// FileData created with h.NewFileData() uses the space's Authorization header by default.
+func (fd *FileData) WithHubToken(token string) *FileData {
+	fd.hubAuth = "Bearer " + token
+	return fd
+}
+
+// WithBase64Encoding sets the base64 variant used by FromBase64 and ToBase64.
+// Defaults to StdBase64.
+func (fd *FileData) WithBase64Encoding(encoding *base64.Encoding) *FileData {
+	fd.encoding = encoding
+	return fd
+}
+
+func (fd *FileData) base64Encoding() *base64.Encoding {
+	if fd.encoding == nil {
+		return StdBase64
+	}
+	return fd.encoding
+}
+
 func (fd *FileData) FromBase64(b64 string) (*FileData, error) {
-	decoded, err := base64.StdEncoding.DecodeString(b64)
+	decoded, err := fd.base64Encoding().DecodeString(b64)
 	if err != nil {
-		return nil, fmt.Errorf("hfs base64 decode: %w", err)
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("hfs base64 decode: %w", err))
 	}
 	return fd.FromBytes(decoded)
 }
 
+// ToBase64 downloads the content of fd's URL and returns it base64 encoded.
+// It is the inverse of FromBase64() and gives up on downloads taking more than 30 seconds.
+func (fd *FileData) ToBase64() (string, error) {
+	return fd.ToBase64WithTimeout(30 * time.Second)
+}
+
+// ToBase64WithTimeout is ToBase64() with a custom download timeout.
+func (fd *FileData) ToBase64WithTimeout(d time.Duration) (string, error) {
+	content, err := FileDataDownload(fd, d, nil)
+	if err != nil {
+		return "", err
+	}
+	return fd.base64Encoding().EncodeToString(content), nil
+}
+
 // Check if src is a FileData.
 // Download content from FileData's URL if so.
 func GetFileData(src any) ([]byte, error) {
+	return GetFileDataWithHeaders(src, nil, 30*time.Second)
+}
+
+// GetFileDataWithHeaders is GetFileData() sending headers with the download,
+// e.g. an Authorization header for files of private spaces.
+func GetFileDataWithHeaders(src any, headers map[string]string, timeout time.Duration) ([]byte, error) {
+	fd, err := toFileData(src)
+	if err != nil {
+		return nil, err
+	}
+	return FileDataDownload(&fd, timeout, headers)
+}
+
+// GetFileData is the package-level GetFileData() sending the space's Authorization header
+// along if WithPropagateAuthToDownloads is set.
+func (h *HFSpace[I, O]) GetFileData(src any) ([]byte, error) {
+	fd, err := toFileData(src)
+	if err != nil {
+		return nil, err
+	}
+	h.authorizeDownload(&fd)
+	return FileDataDownload(&fd, 30*time.Second, nil)
+}
+
+// toFileData converts a FileData, *FileData or decoded JSON output to a FileData.
+func toFileData(src any) (FileData, error) {
 	var fd FileData
 
 	switch v := src.(type) {
@@ -215,63 +1546,231 @@ func GetFileData(src any) ([]byte, error) {
 		fd = v
 	case *FileData:
 		if v == nil {
-			return nil, fmt.Errorf("hfs nil *FileData")
+			return fd, withKind(ErrInvalidFileData, errors.New("hfs nil *FileData"))
 		}
 		fd = *v
 	default:
 		b, err := json.Marshal(src)
 		if err != nil {
-			return nil, fmt.Errorf("hfs filedata json encode: %w", err)
+			return fd, fmt.Errorf("hfs filedata json encode: %w", err)
 		}
 		if err := json.Unmarshal(b, &fd); err != nil {
-			return nil, fmt.Errorf("hfs filedata json decode: %w", err)
+			return fd, withKind(ErrDecodeFailure, fmt.Errorf("hfs filedata json decode: %w", err))
 		}
 	}
-	return FileDataDownload(&fd, 30*time.Second)
+	return fd, nil
 }
 
 // Download content from a FileData's HTTPS URL.
-// Use on output FileData.
-func FileDataDownload(fileData *FileData, timeout time.Duration) ([]byte, error) {
-	// Validate input
-	if fileData == nil {
-		return nil, fmt.Errorf("hfs filedata is nil")
+// Use on output FileData. headers are sent along with those set by WithAuthHeader(); may be nil.
+// timeout limits the whole download and must be positive.
+func FileDataDownload(fileData *FileData, timeout time.Duration, headers map[string]string) ([]byte, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("hfs filedata download timeout must be positive, got %v", timeout)
+	}
+	return fileDataDownload(context.Background(), fileData, timeout, headers)
+}
+
+// fileDataDownload is FileDataDownload() with a context. A zero timeout leaves the limit to ctx.
+func fileDataDownload(ctx context.Context, fileData *FileData, timeout time.Duration, headers map[string]string) ([]byte, error) {
+	resp, err := openFileData(ctx, fileData, &http.Client{Timeout: timeout}, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("hfs filedata get resp read: %w", err)
+	}
+	if len(content) == 0 {
+		return nil, ErrEmptyContent
+	}
+
+	return content, nil
+}
+
+// Reader opens the content of fd's URL for streaming, without loading it into memory.
+// The caller must close it. Use FileDataReader() to pick the client and add headers.
+func (fd *FileData) Reader(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := openFileData(ctx, fd, http.DefaultClient, nil)
+	if err != nil {
+		return nil, err
 	}
+	return resp.Body, nil
+}
 
-	if fileData.URL == "" {
-		return nil, fmt.Errorf("hfs filedata URL is empty")
+// FileDataReader is FileData.Reader() using client, e.g. one with the space's timeout,
+// and sending headers along with those set by WithAuthHeader(). A nil client means http.DefaultClient.
+func FileDataReader(fd *FileData, client *http.Client, headers map[string]string) (io.ReadCloser, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := openFileData(context.Background(), fd, client, headers)
+	if err != nil {
+		return nil, err
 	}
+	return resp.Body, nil
+}
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: timeout * time.Second,
+// openFileData sends the GET for fd's URL and returns the response if it is 2xx.
+func openFileData(ctx context.Context, fd *FileData, client *http.Client, headers map[string]string) (*http.Response, error) {
+	// Validate input
+	if fd == nil {
+		return nil, withKind(ErrInvalidFileData, errors.New("hfs filedata is nil"))
+	}
+	if fd.URL == "" {
+		return nil, withKind(ErrInvalidFileData, errors.New("hfs filedata URL is empty"))
 	}
 
-	// Create the request
-	req, err := http.NewRequest("GET", fileData.URL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fd.URL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("hfs filedata get req create: %w", err)
 	}
+	for k, v := range fd.authHeaders {
+		req.Header.Set(k, v)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 
-	// Send the request
-	resp, err := client.Do(req)
+	resp, err := stripAuthOnRedirect(client, fd.authHeaders).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("hfs filedata get req exec: %w", err)
 	}
-	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("hfs filedata get resp: %w", err)
+	}
+	return resp, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("hfs filedata get resp status: %d %s", resp.StatusCode, resp.Status)
+// stripAuthOnRedirect returns a copy of client that drops the Authorization and Cookie headers,
+// and the auth headers of a FileData, when a redirect leads to another host, e.g. from the Hub
+// to an S3 bucket, so tokens are not leaked to it. The client's own CheckRedirect still runs.
+func stripAuthOnRedirect(client *http.Client, authHeaders map[string]string) *http.Client {
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+			req.Header.Del("Authorization")
+			req.Header.Del("Cookie")
+			for k := range authHeaders {
+				req.Header.Del(k)
+			}
+		}
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
 	}
+	return &c
+}
 
-	// Read the response body
-	content, err := io.ReadAll(resp.Body)
+// SaveTo downloads the content of fd's URL and writes it to path, creating missing parent directories.
+// If path is a directory (or ends with a separator) and fd has an OrigName, the file is saved as OrigName inside it.
+// The content goes to a temporary file that is renamed into place, so path never holds a partial file.
+func (fd *FileData) SaveTo(ctx context.Context, path string) error {
+	if fi, err := os.Stat(path); (err == nil && fi.IsDir()) || strings.HasSuffix(path, string(os.PathSeparator)) {
+		if fd.OrigName == "" {
+			return fmt.Errorf("hfs filedata save: %s is a directory and the file has no name", path)
+		}
+		path = filepath.Join(path, filepath.Base(fd.OrigName))
+	}
+
+	content, err := fileDataDownload(ctx, fd, 0, nil)
 	if err != nil {
-		return nil, fmt.Errorf("hfs filedata get resp read: %w", err)
+		return err
 	}
-	if len(content) == 0 {
-		return nil, fmt.Errorf("hfs downloaded content is empty")
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("hfs filedata save dir create: %w", err)
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("hfs filedata save temp create: %w", err)
+	}
+	_, err = f.Write(content)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
 	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("hfs filedata save: %w", err)
+	}
+	return nil
+}
 
-	return content, nil
+// DownloadOptions configures FileData.DownloadToFile.
+type DownloadOptions struct {
+	// ProgressCallback is called after every chunk written.
+	// total is -1 when the server does not report a Content-Length.
+	ProgressCallback func(downloaded, total int64)
+	// ChunkSize is the copy buffer size in bytes. Defaults to 32 KiB.
+	ChunkSize int
+	// Overwrite allows replacing an existing file at the destination path.
+	Overwrite bool
+}
+
+// DownloadToFile streams the content of fd's URL to path without holding it in memory.
+// If the download is interrupted, the partial file is left in place and the error is returned
+// together with the number of bytes written so far.
+func (fd *FileData) DownloadToFile(ctx context.Context, path string, opts DownloadOptions) (written int64, err error) {
+	resp, err := openFileData(ctx, fd, http.DefaultClient, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !opts.Overwrite {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("hfs filedata file create: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("hfs filedata file close: %w", cerr)
+		}
+	}()
+
+	chunk := opts.ChunkSize
+	if chunk <= 0 {
+		chunk = 32 * 1024
+	}
+	pw := &progressWriter{w: f, total: resp.ContentLength, fn: opts.ProgressCallback}
+	written, err = io.CopyBuffer(pw, resp.Body, make([]byte, chunk))
+	if err != nil {
+		return written, fmt.Errorf("hfs filedata download interrupted after %d bytes: %w", written, err)
+	}
+	return written, nil
+}
+
+// progressWriter reports the running byte count after every write.
+type progressWriter struct {
+	w     io.Writer
+	n     int64
+	total int64
+	fn    func(downloaded, total int64)
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.n += int64(n)
+	if pw.fn != nil {
+		pw.fn(pw.n, pw.total)
+	}
+	return n, err
 }
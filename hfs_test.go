@@ -1,8 +1,24 @@
 package hfs
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -13,76 +29,1694 @@ var test_endpoint = "/infer"                      // your HF Space endpoint, e.g
 var test_input_url = "https://i.pinimg.com/474x/c2/c3/d2/c2c3d23c592772cafa4bad0d64d51416.jpg"
 var test_prompt = "make it smile"
 
-func Test_FileDataFromURL(t *testing.T) {
-	t.Parallel()
+// fakeGradio serves a minimal Gradio call API: every POST gets an event ID,
+// every GET gets sse as the event stream. POSTs are counted in posts if non-nil.
+func fakeGradio(t *testing.T, sse string, posts *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if posts != nil {
+				posts.Add(1)
+			}
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte(sse))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestHfs returns an HFSpace pointed at srv instead of hf.space.
+func newTestHfs[I, O any](srv *httptest.Server) *HFSpace[I, O] {
+	h := NewHfs[I, O]("test")
+	h.BaseURL = srv.URL + "/gradio_api/call"
+	return h
+}
+
+func Test_DeduplicationWindow(t *testing.T) {
+	var posts atomic.Int32
+	srv := fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", &posts)
+	hfs := newTestHfs[string, string](srv).WithDeduplicationWindow(time.Second)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := hfs.Do(test_endpoint, "same")
+			if err != nil {
+				t.Errorf("Do() returned error: %v", err)
+				return
+			}
+			if len(res) != 1 || res[0] != "ok" {
+				t.Errorf("unexpected result: %v", res)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := hfs.Do(test_endpoint, "same"); err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+	if n := posts.Load(); n != 1 {
+		t.Fatalf("expected 1 POST within window, got %d", n)
+	}
+
+	if _, err := hfs.Do(test_endpoint, "different"); err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+	if n := posts.Load(); n != 2 {
+		t.Fatalf("expected 2 POSTs after distinct request, got %d", n)
+	}
+}
+
+func Test_DeduplicationContext(t *testing.T) {
+	var posts atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts.Add(1)
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[string, string](srv).WithDeduplicationWindow(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := hfs.DoWithContext(ctx, test_endpoint, "same")
+		firstErr <- err
+	}()
+	for posts.Load() == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+	start := time.Now()
+	if _, err := hfs.DoWithContext(short, test_endpoint, "same"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the waiter's own deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the waiter to give up at its deadline, waited %v", elapsed)
+	}
+
+	type result struct {
+		res []string
+		err error
+	}
+	live := make(chan result, 1)
+	go func() {
+		res, err := hfs.DoWithContext(context.Background(), test_endpoint, "same")
+		live <- result{res, err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the first call to be cancelled, got %v", err)
+	}
+	close(release)
+	r := <-live
+	if r.err != nil || len(r.res) != 1 || r.res[0] != "ok" {
+		t.Fatalf("expected the live waiter to run its own request, got %v, %v", r.res, r.err)
+	}
+	if n := posts.Load(); n != 2 {
+		t.Fatalf("expected 2 POSTs, got %d", n)
+	}
+}
+
+func Test_DeduplicationMaxSize(t *testing.T) {
+	var posts atomic.Int32
+	srv := fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", &posts)
+	hfs := newTestHfs[string, string](srv).WithDeduplication(time.Minute).WithDeduplicationMaxSize(2)
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := hfs.Do(test_endpoint, "a"); err != nil {
+				t.Errorf("Do() returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := posts.Load(); n != 1 {
+		t.Fatalf("expected 2 concurrent identical calls to send 1 POST, got %d", n)
+	}
+
+	// a, b and c do not fit: touching a makes b the least recently used.
+	for _, p := range []string{"b", "a", "c", "a", "b"} {
+		if _, err := hfs.Do(test_endpoint, p); err != nil {
+			t.Fatalf("Do() returned error: %v", err)
+		}
+	}
+	if n := posts.Load(); n != 4 {
+		t.Fatalf("expected POSTs for a, b, c and the evicted b, got %d", n)
+	}
+}
+
+func Test_ErrorHandler(t *testing.T) {
+	srv := fakeGradio(t, "event: error\ndata: null\n\n", nil)
+
+	hfs := newTestHfs[string, string](srv).WithErrorHandler(func(err error) ([]string, error) {
+		return []string{"fallback"}, nil
+	})
+	res, err := hfs.Do(test_endpoint, "x")
+	if err != nil {
+		t.Fatalf("Do() returned error despite recovery: %v", err)
+	}
+	if len(res) != 1 || res[0] != "fallback" {
+		t.Fatalf("expected recovered result, got %v", res)
+	}
+
+	hfs.WithErrorHandler(func(err error) ([]string, error) {
+		return nil, fmt.Errorf("handled: %w", err)
+	})
+	if _, err := hfs.Do(test_endpoint, "x"); err == nil || !strings.HasPrefix(err.Error(), "handled: ") {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+}
+
+func Test_ClientIsolation(t *testing.T) {
+	a := NewHfs[any, any](test_name).WithTimeout(5 * time.Second)
+	b := NewHfs[any, any](test_name)
+
+	if a.client == b.client {
+		t.Fatalf("expected each HFSpace to own its http.Client")
+	}
+	if a.client == http.DefaultClient || b.client == http.DefaultClient {
+		t.Fatalf("expected HFSpace not to use http.DefaultClient")
+	}
+	if http.DefaultClient.Timeout != 0 {
+		t.Fatalf("WithTimeout leaked into http.DefaultClient: %v", http.DefaultClient.Timeout)
+	}
+	if b.client.Timeout != 0 {
+		t.Fatalf("WithTimeout leaked into another HFSpace: %v", b.client.Timeout)
+	}
+	if a.client.Transport == http.DefaultTransport || a.client.Transport == b.client.Transport {
+		t.Fatalf("expected each HFSpace to own its transport")
+	}
+}
+
+func Test_Clone(t *testing.T) {
+	var mu sync.Mutex
+	auths := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			mu.Lock()
+			auths[r.Header.Get("Authorization")]++
+			mu.Unlock()
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	orig := newTestHfs[any, string](srv).WithBearerToken("alice")
+	clone := orig.Clone().WithBearerToken("bob").WithTimeout(time.Minute)
+
+	if orig.Headers["Authorization"] != "Bearer alice" || clone.Headers["Authorization"] != "Bearer bob" {
+		t.Fatalf("expected independent headers, got %q and %q", orig.Headers["Authorization"], clone.Headers["Authorization"])
+	}
+	if orig.client.Timeout != 0 {
+		t.Fatalf("WithTimeout on the clone leaked into the original: %v", orig.client.Timeout)
+	}
+	if orig.client.Transport != clone.client.Transport {
+		t.Fatalf("expected the clone to share the transport")
+	}
 
-	hfs := NewHfs[any, any](test_name)
-	hfs.WithTimeout(300 * time.Second)
-	hfs.WithBearerToken(test_hf_token)
+	var wg sync.WaitGroup
+	for _, h := range []*HFSpace[any, string]{orig, clone, orig, clone} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := h.Do(test_endpoint); err != nil || len(res) != 1 || res[0] != "ok" {
+				t.Errorf("Do() returned %v, %v", res, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if auths["Bearer alice"] != 2 || auths["Bearer bob"] != 2 {
+		t.Fatalf("unexpected Authorization headers %v", auths)
+	}
+}
+
+func Test_DownloadToFile(t *testing.T) {
+	content := bytes.Repeat([]byte("hfs"), 10000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	fd, err := NewFileData("out.bin").FromUrl(srv.URL)
+	if err != nil {
+		t.Fatalf("FromUrl() returned error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "out.bin")
 
-	fdi, err := NewFileData("").FromUrl(test_input_url)
+	var calls int
+	var last int64
+	written, err := fd.DownloadToFile(context.Background(), path, DownloadOptions{
+		ChunkSize: 4096,
+		ProgressCallback: func(downloaded, total int64) {
+			calls++
+			if downloaded < last {
+				t.Errorf("progress went backwards: %d after %d", downloaded, last)
+			}
+			last = downloaded
+		},
+	})
 	if err != nil {
-		t.Fatalf("ToFileData returned error: %v", err)
+		t.Fatalf("DownloadToFile() returned error: %v", err)
+	}
+	if written != int64(len(content)) || last != written {
+		t.Fatalf("expected %d bytes written, got %d (last progress %d)", len(content), written, last)
+	}
+	if calls < 2 {
+		t.Fatalf("expected progress per chunk, got %d calls", calls)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("downloaded file does not match served content")
+	}
+
+	if _, err := fd.DownloadToFile(context.Background(), path, DownloadOptions{}); err == nil {
+		t.Fatalf("expected error when file exists and Overwrite is false")
+	}
+	if _, err := fd.DownloadToFile(context.Background(), path, DownloadOptions{Overwrite: true}); err != nil {
+		t.Fatalf("DownloadToFile() with Overwrite returned error: %v", err)
+	}
+}
+
+func Test_SaveTo(t *testing.T) {
+	content := bytes.Repeat([]byte("hfs"), 10000)
+	half := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		close(half)
+		<-release
+		w.Write(content[len(content)/2:])
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "out.bin")
+	fd, _ := NewFileData("").FromUrl(srv.URL)
+
+	done := make(chan error, 1)
+	go func() { done <- fd.SaveTo(context.Background(), path) }()
+
+	<-half
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no file at %s mid-download, got %v", path, err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("SaveTo() returned error: %v", err)
 	}
 
-	res, err := hfs.Do(test_endpoint, fdi, test_prompt, 0, true, 2.5, 1 /*28*/)
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("saved file differs from download: got %d bytes, expected %d", len(got), len(content))
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0644 {
+		t.Fatalf("expected mode 0644, got %v", fi.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected temp file to be gone, got %d entries", len(entries))
+	}
+}
+
+func Test_SaveToDirectory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("png"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	fd, _ := NewFileData("").FromUrl(srv.URL)
+	fd.OrigName = "image.png"
+	if err := fd.SaveTo(context.Background(), dir); err != nil {
+		t.Fatalf("SaveTo() returned error: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "image.png")); err != nil || string(got) != "png" {
+		t.Fatalf("expected image.png inside dir, got %q, %v", got, err)
+	}
+
+	fd.OrigName = ""
+	if err := fd.SaveTo(context.Background(), dir); err == nil {
+		t.Fatalf("expected error saving an unnamed file to a directory")
+	}
+}
+
+func Test_EventIDField(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"id":"custom"}`))
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/custom") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [1]\n\n"))
+	}))
+	defer srv.Close()
+
+	hfs := newTestHfs[int, int](srv).WithEventIDField("id")
+	res, err := hfs.Do(test_endpoint, 1)
 	if err != nil {
 		t.Fatalf("Do() returned error: %v", err)
 	}
-	if len(res) == 0 {
-		t.Fatalf("expected at least one result from Do()")
+	if len(res) != 1 || res[0] != 1 {
+		t.Fatalf("unexpected result: %v", res)
 	}
 
-	var out []byte
-	out, err = GetFileData(res[0])
+	if _, err := newTestHfs[int, int](srv).Do(test_endpoint, 1); err == nil {
+		t.Fatalf("expected error when default event_id field is missing")
+	}
+}
+
+func Test_ProgressCallback(t *testing.T) {
+	sse := "event: generating\n" +
+		`data: {"msg": "progress", "progress_data": [{"value": 3, "length": 10, "desc": "Generating", "unit": "steps"}]}` + "\n\n" +
+		"event: generating\n" +
+		`data: {"msg": "progress", "progress_data": [{"index": 4, "length": 10, "desc": "Generating", "unit": "steps"}]}` + "\n\n" +
+		"event: complete\ndata: [\"done\"]\n\n"
+	srv := fakeGradio(t, sse, nil)
+
+	var got []string
+	hfs := newTestHfs[any, string](srv).WithProgressCallback(func(p ProgressEvent) {
+		got = append(got, p.String())
+	})
+	res, err := hfs.Do(test_endpoint)
 	if err != nil {
-		t.Fatalf("GetFileData() returned error: %v", err)
+		t.Fatalf("Do() returned error: %v", err)
+	}
+	if len(res) != 1 || res[0] != "done" {
+		t.Fatalf("unexpected result: %v", res)
+	}
+	want := []string{"Generating: 3/10 steps", "Generating: 4/10 steps"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected progress %v, got %v", want, got)
 	}
-	if len(out) == 0 {
-		t.Fatalf("expected non-empty output")
+}
+
+func Test_ToBase64Encoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0xfb, 0xff})
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		encoding *base64.Encoding
+		want     string
+	}{
+		{nil, "+/8="},
+		{StdBase64, "+/8="},
+		{URLBase64, "-_8="},
+		{RawStdBase64, "+/8"},
+		{RawURLBase64, "-_8"},
 	}
+	for _, c := range cases {
+		fd, _ := NewFileData("").FromUrl(srv.URL)
+		if c.encoding != nil {
+			fd.WithBase64Encoding(c.encoding)
+		}
+		got, err := fd.ToBase64()
+		if err != nil {
+			t.Fatalf("ToBase64() returned error: %v", err)
+		}
+		if got != c.want {
+			t.Fatalf("expected %q, got %q", c.want, got)
+		}
+	}
+}
+
+// memoryUploader keeps uploads in memory and serves them back from srv.
+type memoryUploader struct {
+	srv   *httptest.Server
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newMemoryUploader(t *testing.T) *memoryUploader {
+	u := &memoryUploader{files: map[string][]byte{}}
+	u.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		w.Write(u.files[r.URL.Path])
+	}))
+	t.Cleanup(u.srv.Close)
+	return u
+}
+
+func (u *memoryUploader) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.files["/"+name] = data
+	return u.srv.URL + "/" + name, nil
 }
 
-func Test_FileDataFromBytes(t *testing.T) {
-	t.Parallel()
-	hfs := NewHfs[any, any](test_name)
-	hfs.WithTimeout(300 * time.Second)
-	hfs.WithBearerToken(test_hf_token)
-	resp, err := http.Get(test_input_url)
+func Test_ToBase64RoundTrip(t *testing.T) {
+	png, err := os.ReadFile("testdata/pixel.png")
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	b64 := base64.StdEncoding.EncodeToString(png)
+
+	fd, err := NewFileData("pixel.png").WithUploader(newMemoryUploader(t)).FromBase64(b64)
+	if err != nil {
+		t.Fatalf("FromBase64() returned error: %v", err)
+	}
+	got, err := fd.ToBase64()
+	if err != nil {
+		t.Fatalf("ToBase64() returned error: %v", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(got)
 	if err != nil {
-		t.Fatalf("http.Get() returned error: %v", err)
+		t.Fatalf("ToBase64() returned invalid base64: %v", err)
+	}
+	if !bytes.Equal(decoded, png) {
+		t.Fatalf("round trip changed the content: got %d bytes, expected %d", len(decoded), len(png))
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("http.Get() returned status code %d, expected 200", resp.StatusCode)
+
+	if got, err = fd.ToBase64WithTimeout(5 * time.Second); err != nil || got != b64 {
+		t.Fatalf("ToBase64WithTimeout() returned %q, %v", got, err)
 	}
-	data_reader := resp.Body
-	data, err := io.ReadAll(data_reader)
+}
+
+func Test_SubmitFetchResult(t *testing.T) {
+	var posts atomic.Int32
+	srv := fakeGradio(t, "event: complete\ndata: [\"later\"]\n\n", &posts)
+	hfs := newTestHfs[string, string](srv)
+
+	eventID, err := hfs.Submit(context.Background(), test_endpoint, "job")
 	if err != nil {
-		t.Fatalf("io.ReadAll() returned error: %v", err)
+		t.Fatalf("Submit() returned error: %v", err)
 	}
-	if len(data) == 0 {
-		t.Fatalf("expected non-empty input data")
+	if eventID != "evt" {
+		t.Fatalf("expected event ID %q, got %q", "evt", eventID)
 	}
 
-	fdi, err := NewFileData("").FromBytes(data)
+	res, err := hfs.FetchResult(context.Background(), test_endpoint, eventID)
 	if err != nil {
-		t.Fatalf("ToFileData returned error: %v", err)
+		t.Fatalf("FetchResult() returned error: %v", err)
+	}
+	if len(res) != 1 || res[0] != "later" {
+		t.Fatalf("unexpected result: %v", res)
 	}
+	if n := posts.Load(); n != 1 {
+		t.Fatalf("expected exactly 1 POST, got %d", n)
+	}
+}
 
-	res, err := hfs.Do(test_endpoint, fdi, test_prompt, 0, true, 2.5, 1 /*28*/)
+func Test_ClientGeneratedEventIDs(t *testing.T) {
+	var mu sync.Mutex
+	var postedID, gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body struct {
+				EventID string `json:"event_id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			postedID = body.EventID
+			mu.Unlock()
+			w.Write([]byte(`{"event_id":"` + body.EventID + `"}`))
+			return
+		}
+		mu.Lock()
+		gotPath = r.URL.Path
+		mu.Unlock()
+		w.Write([]byte("event: complete\ndata: [\"fast\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	hfs := newTestHfs[string, string](srv).WithClientGeneratedEventIDs()
+	res, err := hfs.Do(test_endpoint, "x")
 	if err != nil {
 		t.Fatalf("Do() returned error: %v", err)
 	}
-	if len(res) == 0 {
-		t.Fatalf("expected at least one result from Do()")
+	if len(res) != 1 || res[0] != "fast" {
+		t.Fatalf("unexpected result: %v", res)
+	}
+	if len(postedID) != 32 {
+		t.Fatalf("expected 32 hex digit event ID in POST body, got %q", postedID)
+	}
+	if !strings.HasSuffix(gotPath, "/"+postedID) {
+		t.Fatalf("expected GET for %q, got %q", postedID, gotPath)
+	}
+}
+
+func Test_FileDataClone(t *testing.T) {
+	fd := NewFileData("in.png", "image/png").WithAuthHeader("Authorization", "Bearer a")
+	c := fd.Clone()
+	if !reflect.DeepEqual(c, fd) {
+		t.Fatalf("expected an equal copy, got %+v", c)
+	}
+
+	// Run with -race: the copies must not share anything that is written.
+	var wg sync.WaitGroup
+	for i, f := range []*FileData{fd, c} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				f.Meta["n"] = j
+				f.WithAuthHeader("X-Copy", fmt.Sprint(i))
+				*f.MimeType = fmt.Sprintf("image/%d", i)
+			}
+		}()
+	}
+	wg.Wait()
+	if *fd.MimeType != "image/0" || *c.MimeType != "image/1" || fd.authHeaders["X-Copy"] != "0" || c.authHeaders["X-Copy"] != "1" {
+		t.Fatalf("expected independent copies, got %+v and %+v", fd, c)
+	}
+}
+
+func Test_FileDataAuthHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Token") != "signed" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	fd, _ := NewFileData("").FromUrl(srv.URL)
+	if _, err := GetFileData(fd); err == nil {
+		t.Fatalf("expected download without auth header to fail")
+	}
+
+	fd.WithAuthHeader("X-Amz-Token", "signed")
+	out, err := GetFileData(fd)
+	if err != nil {
+		t.Fatalf("GetFileData() returned error: %v", err)
+	}
+	if string(out) != "secret" {
+		t.Fatalf("unexpected content: %q", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "secret.txt")
+	if _, err := fd.DownloadToFile(context.Background(), path, DownloadOptions{}); err != nil {
+		t.Fatalf("DownloadToFile() returned error: %v", err)
+	}
+}
+
+func Test_GetFileDataWithHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hf_x" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	src := map[string]any{"path": "/tmp/a.png", "url": srv.URL + "/a.png"}
+	if _, err := GetFileData(src); err == nil {
+		t.Fatalf("expected download without headers to fail")
+	}
+	out, err := GetFileDataWithHeaders(src, map[string]string{"Authorization": "Bearer hf_x"}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("GetFileDataWithHeaders() returned error: %v", err)
+	}
+	if string(out) != "secret" {
+		t.Fatalf("unexpected content: %q", out)
+	}
+}
+
+func Test_FileDataDownloadRedirect(t *testing.T) {
+	var leaked atomic.Value
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked.Store(r.Header.Get("Authorization") + r.Header.Get("X-Api-Key"))
+		w.Write([]byte("content"))
+	}))
+	defer cdn.Close()
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hf_x" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/file", http.StatusFound)
+		case "/file":
+			w.Write([]byte("own content"))
+		default:
+			http.Redirect(w, r, cdn.URL+"/bucket/file", http.StatusFound)
+		}
+	}))
+	defer hub.Close()
+
+	fd := &FileData{URL: hub.URL + "/resolve/file"}
+	fd.WithAuthHeader("X-Api-Key", "key")
+	out, err := FileDataDownload(fd, 5*time.Second, map[string]string{"Authorization": "Bearer hf_x"})
+	if err != nil || string(out) != "content" {
+		t.Fatalf("FileDataDownload() returned %q, %v", out, err)
+	}
+	if got := leaked.Load(); got != "" {
+		t.Fatalf("expected no auth headers at the redirect target, got %q", got)
+	}
+
+	fd = &FileData{URL: hub.URL + "/same"}
+	out, err = FileDataDownload(fd, 5*time.Second, map[string]string{"Authorization": "Bearer hf_x"})
+	if err != nil || string(out) != "own content" {
+		t.Fatalf("expected auth to be kept on the same host, got %q, %v", out, err)
+	}
+}
+
+func Test_PropagateAuthToDownloads(t *testing.T) {
+	var foreignAuth atomic.Value
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignAuth.Store(r.Header.Get("Authorization"))
+		w.Write([]byte("public"))
+	}))
+	defer foreign.Close()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"event_id":"evt"}`))
+		case strings.HasPrefix(r.URL.Path, "/gradio_api/call/"):
+			fmt.Fprintf(w, "event: complete\ndata: [{\"path\": \"a.png\", \"url\": \"%s/gradio_api/file=a.png\"}, {\"path\": \"b.png\", \"url\": \"%s/b.png\"}]\n\n", srv.URL, foreign.URL)
+		case r.Header.Get("Authorization") != "Bearer hf_x":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		default:
+			w.Write([]byte("private"))
+		}
+	}))
+	defer srv.Close()
+
+	res, err := newTestHfs[any, *FileData](srv).WithBearerToken("hf_x").Do(test_endpoint)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if _, err := GetFileData(res[0]); err == nil {
+		t.Fatalf("expected download without propagation to fail")
 	}
-	var out []byte
-	out, err = GetFileData(res[0])
+
+	hfs := newTestHfs[any, *FileData](srv).WithBearerToken("hf_x").WithPropagateAuthToDownloads()
+	res, err = hfs.Do(test_endpoint)
 	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if out, err := GetFileData(res[0]); err != nil || string(out) != "private" {
+		t.Fatalf("expected the token to be sent with the download, got %q, %v", out, err)
+	}
+	if _, err := GetFileData(res[1]); err != nil {
 		t.Fatalf("GetFileData() returned error: %v", err)
 	}
-	if len(out) == 0 {
-		t.Fatalf("expected non-empty output")
+	if got := foreignAuth.Load(); got != "" {
+		t.Fatalf("expected no token sent to another host, got %q", got)
+	}
+
+	untyped, err := newTestHfs[any, any](srv).WithBearerToken("hf_x").WithPropagateAuthToDownloads().Do(test_endpoint)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if out, err := hfs.GetFileData(untyped[0]); err != nil || string(out) != "private" {
+		t.Fatalf("expected h.GetFileData() to send the token, got %q, %v", out, err)
+	}
+}
+
+func Test_FileDataDownloadTimeout(t *testing.T) {
+	hang := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-hang:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(hang)
+
+	fd, _ := NewFileData("").FromUrl(srv.URL)
+	start := time.Now()
+	if _, err := FileDataDownload(fd, 500*time.Millisecond, nil); err == nil {
+		t.Fatalf("expected timeout error from a server that never responds")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the download to give up within 1s, took %v", elapsed)
+	}
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		if _, err := FileDataDownload(fd, d, nil); err == nil {
+			t.Fatalf("expected error for timeout %v", d)
+		}
+	}
+}
+
+func Test_RequestTransform(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.Header.Get("X-Order")+" "+r.Header.Get("X-Body-Len"))
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [1]\n\n"))
+	}))
+	defer srv.Close()
+
+	hfs := newTestHfs[int, int](srv).
+		WithRequestTransform(func(r *http.Request) (*http.Request, error) {
+			r.Header.Set("X-Order", "first")
+			if r.GetBody != nil {
+				body, err := r.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				b, _ := io.ReadAll(body)
+				r.Header.Set("X-Body-Len", fmt.Sprint(len(b)))
+			}
+			return r, nil
+		}).
+		WithRequestTransform(func(r *http.Request) (*http.Request, error) {
+			r.Header.Set("X-Order", r.Header.Get("X-Order")+",second")
+			return r, nil
+		})
+
+	if _, err := hfs.Do(test_endpoint, 1); err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+	want := []string{"POST first,second 12", "GET first,second "}
+	if strings.Join(seen, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+
+	hfs.WithRequestTransform(func(r *http.Request) (*http.Request, error) {
+		return nil, fmt.Errorf("no signing key")
+	})
+	if _, err := hfs.Do(test_endpoint, 1); err == nil || !strings.Contains(err.Error(), "no signing key") {
+		t.Fatalf("expected transform error, got %v", err)
+	}
+}
+
+func Test_HTTPCacheControl(t *testing.T) {
+	var posts atomic.Int32
+	srv := fakeGradio(t, "event: complete\ndata: [\"cached\"]\n\n", &posts)
+
+	cache := NewMemoryHTTPCache()
+	a := newTestHfs[string, string](srv).WithHTTPCacheControl(cache)
+	b := newTestHfs[string, string](srv).WithHTTPCacheControl(cache)
+
+	for _, hfs := range []*HFSpace[string, string]{a, a, b} {
+		res, err := hfs.Do(test_endpoint, "prompt")
+		if err != nil {
+			t.Fatalf("Do() returned error: %v", err)
+		}
+		if len(res) != 1 || res[0] != "cached" {
+			t.Fatalf("unexpected result: %v", res)
+		}
+	}
+	if n := posts.Load(); n != 1 {
+		t.Fatalf("expected 1 POST with shared cache, got %d", n)
+	}
+
+	if _, err := a.Do(test_endpoint, "other prompt"); err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+	if n := posts.Load(); n != 2 {
+		t.Fatalf("expected cache miss for a different body, got %d POSTs", n)
+	}
+}
+
+func Test_ConnectionReuse(t *testing.T) {
+	var mu sync.Mutex
+	var addrs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		addrs = append(addrs, r.RemoteAddr)
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			// Trailing whitespace after the JSON value is left unread by the decoder.
+			w.Write([]byte(`{"event_id":"evt"}`))
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+			w.Write(bytes.Repeat([]byte("\n"), 16*1024))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [1]\n\n"))
+	}))
+	defer srv.Close()
+
+	hfs := newTestHfs[int, int](srv).WithConnectionReuse()
+	for range 2 {
+		if _, err := hfs.Do(test_endpoint, 1); err != nil {
+			t.Fatalf("Do() returned error: %v", err)
+		}
+	}
+	for _, addr := range addrs[1:] {
+		if addr != addrs[0] {
+			t.Fatalf("expected all requests on one connection, got %v", addrs)
+		}
+	}
+
+	shared := &http.Transport{DisableKeepAlives: true}
+	hfs = newTestHfs[int, int](srv).WithHTTPClient(&http.Client{Transport: shared}).WithConnectionReuse()
+	if !shared.DisableKeepAlives {
+		t.Fatalf("expected the transport of the given client to be left unchanged")
+	}
+	if hfs.client.Transport.(*http.Transport).DisableKeepAlives {
+		t.Fatalf("expected keep-alives enabled on the space's transport")
+	}
+}
+
+func Test_GeneratingTimeout(t *testing.T) {
+	stall := make(chan struct{})
+	defer close(stall)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		for range 3 {
+			w.Write([]byte("event: generating\ndata: [\"partial\"]\n\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(30 * time.Millisecond)
+		}
+		if strings.HasSuffix(r.URL.Path, "/ok/evt") {
+			w.Write([]byte("event: complete\ndata: [\"done\"]\n\n"))
+			return
+		}
+		select {
+		case <-stall:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	hfs := newTestHfs[any, string](srv).WithGeneratingTimeout(100 * time.Millisecond)
+
+	res, err := hfs.Do("/ok")
+	if err != nil {
+		t.Fatalf("Do() returned error for steady stream: %v", err)
+	}
+	if len(res) != 1 || res[0] != "done" {
+		t.Fatalf("unexpected result: %v", res)
+	}
+
+	start := time.Now()
+	_, err = hfs.Do("/stalled")
+	if !errors.Is(err, ErrGeneratingTimeout) || !errors.Is(err, ErrIdleTimeout) {
+		t.Fatalf("expected ErrGeneratingTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("stalled stream took %v to time out", elapsed)
+	}
+}
+
+func Test_DoWithContextCancel(t *testing.T) {
+	inGet := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/slow-post") {
+			// The server only notices the client going away once the body is consumed.
+			io.Copy(io.Discard, r.Body)
+			<-r.Context().Done()
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: generating\ndata: [\"partial\"]\n\n"))
+		w.(http.Flusher).Flush()
+		inGet <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, any](srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := hfs.DoWithContext(ctx, "/slow-post"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded during POST, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		<-inGet
+		cancel()
+	}()
+	if _, err := hfs.DoWithContext(ctx, "/slow-get"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled during GET, got %v", err)
+	}
+}
+
+func Test_Retry(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if posts.Add(1) <= 2 || strings.HasSuffix(r.URL.Path, "/down") {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	var attempts []int
+	hfs := newTestHfs[string, string](srv).
+		WithRetry(3, time.Millisecond, 2).
+		WithRetryJitter(0.5).
+		WithRetryCallback(func(attempt int, err error) {
+			attempts = append(attempts, attempt)
+		})
+
+	res, err := hfs.Do("/predict")
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("unexpected result: %v", res)
+	}
+	if posts.Load() != 3 || len(attempts) != 2 {
+		t.Fatalf("expected success on attempt 3, got %d posts and retries %v", posts.Load(), attempts)
+	}
+
+	posts.Store(0)
+	attempts = nil
+	_, err = hfs.Do("/down")
+	var herr *HTTPStatusError
+	if !errors.As(err, &herr) || herr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 after exhausting attempts, got %v", err)
+	}
+	if posts.Load() != 3 || len(attempts) != 2 {
+		t.Fatalf("expected 3 attempts, got %d posts and retries %v", posts.Load(), attempts)
+	}
+}
+
+func Test_FileDataFromReader(t *testing.T) {
+	data := make([]byte, 5<<20)
+	rand.Read(data)
+
+	fd := NewFileData("")
+	fd.WithUploader(fakeQuax(t).AsUploader())
+	fd, err := fd.FromReader(context.Background(), bytes.NewReader(data), "video.mp4")
+	if err != nil {
+		t.Fatalf("FromReader returned error: %v", err)
+	}
+	if want := fmt.Sprintf("https://qu.ax/%d/video.mp4", len(data)); fd.URL != want {
+		t.Fatalf("expected URL %q, got %q", want, fd.URL)
+	}
+	if fd.Size != int64(len(data)) || fd.OrigName != "video.mp4" {
+		t.Fatalf("unexpected size %d or name %q", fd.Size, fd.OrigName)
+	}
+
+	fd = NewFileData("stream.bin")
+	fd.WithUploader(fakeQuax(t).AsUploader())
+	fd, err = fd.FromReader(context.Background(), io.LimitReader(bytes.NewReader(data), 1024), "stream.bin")
+	if err != nil {
+		t.Fatalf("FromReader returned error: %v", err)
+	}
+	if fd.Size != 0 || fd.URL == "" {
+		t.Fatalf("expected no size for non-seekable reader, got %d", fd.Size)
+	}
+}
+
+func Test_FileDataFromHTTPResponse(t *testing.T) {
+	content := []byte("%PDF-1.4 report")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf; charset=binary")
+		w.Header().Set("Content-Disposition", `attachment; filename="report.pdf"`)
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/download?id=1")
+	if err != nil {
+		t.Fatalf("http.Get returned error: %v", err)
+	}
+	u := newMemoryUploader(t)
+	fd, err := NewFileData("").WithUploader(u).FromHTTPResponse(resp)
+	if err != nil {
+		t.Fatalf("FromHTTPResponse returned error: %v", err)
+	}
+	if fd.OrigName != "report.pdf" || fd.MimeType == nil || *fd.MimeType != "application/pdf" || fd.Size != int64(len(content)) {
+		t.Fatalf("unexpected FileData %+v", fd)
+	}
+	if fd.URL != u.srv.URL+"/report.pdf" || !bytes.Equal(u.files["/report.pdf"], content) {
+		t.Fatalf("expected the body to be uploaded, got %q at %s", u.files["/report.pdf"], fd.URL)
+	}
+	if _, err := resp.Body.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("expected the body to be closed")
+	}
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	resp, err = http.Get(missing.URL)
+	if err != nil {
+		t.Fatalf("http.Get returned error: %v", err)
+	}
+	if _, err := NewFileData("").WithUploader(u).FromHTTPResponse(resp); !errors.Is(err, ErrHTTPStatus) {
+		t.Fatalf("expected ErrHTTPStatus for a 404, got %v", err)
+	}
+}
+
+func Test_FileDataFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.html")
+	content := []byte("<!DOCTYPE html><html><body>hi</body></html>")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fd := NewFileData("")
+	fd.WithUploader(fakeQuax(t).AsUploader())
+	fd, err := fd.FromFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("FromFile returned error: %v", err)
+	}
+	if fd.URL != fmt.Sprintf("https://qu.ax/%d/page.html", len(content)) || fd.Size != int64(len(content)) {
+		t.Fatalf("unexpected URL %q or size %d", fd.URL, fd.Size)
+	}
+	if fd.OrigName != "page.html" || fd.MimeType == nil || *fd.MimeType != "text/html; charset=utf-8" {
+		t.Fatalf("unexpected name %q or mime type %v", fd.OrigName, fd.MimeType)
+	}
+
+	mem := newMemoryUploader(t)
+	if _, err := NewFileData("").WithUploader(mem).FromFile(context.Background(), path); err != nil {
+		t.Fatalf("FromFile returned error: %v", err)
+	}
+	if _, ok := mem.files["/page.html"]; !ok || len(mem.files) != 1 {
+		t.Fatalf("expected the upload to be named after the file alone, got %v", slices.Collect(maps.Keys(mem.files)))
+	}
+
+	fd = NewFileData("")
+	fd.WithUploader(fakeQuax(t).AsUploader())
+	if _, err := fd.WithUploadMaxBytes(10).FromFile(context.Background(), path); err == nil || !strings.Contains(err.Error(), "upload limit") {
+		t.Fatalf("expected upload limit error, got %v", err)
+	}
+
+	if _, err := NewFileData("").FromFile(context.Background(), filepath.Join(dir, "missing.png")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func Test_FileDataMimeType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+	mp3 := []byte("ID3\x03\x00\x00\x00\x00\x00\x00\xff\xfb\x90\x00")
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"image.png", png, "image/png"},
+		{"audio.mp3", mp3, "audio/mpeg"},
+	} {
+		fd := NewFileData(tc.name)
+		fd.WithUploader(fakeQuax(t).AsUploader())
+		fd, err := fd.FromBytes(tc.data)
+		if err != nil {
+			t.Fatalf("FromBytes returned error: %v", err)
+		}
+		if fd.MimeType == nil || *fd.MimeType != tc.want {
+			t.Fatalf("expected mime type %q for %s, got %v", tc.want, tc.name, fd.MimeType)
+		}
+
+		fd = NewFileData(tc.name)
+		fd.WithUploader(fakeQuax(t).AsUploader())
+		fd, err = fd.FromReader(context.Background(), bytes.NewReader(tc.data), tc.name)
+		if err != nil {
+			t.Fatalf("FromReader returned error: %v", err)
+		}
+		if fd.MimeType == nil || *fd.MimeType != tc.want {
+			t.Fatalf("expected mime type %q for %s, got %v", tc.want, tc.name, fd.MimeType)
+		}
+	}
+
+	fd := NewFileData("image.png", "image/x-custom")
+	fd.WithUploader(fakeQuax(t).AsUploader())
+	if fd, _ = fd.FromBytes(png); fd == nil || *fd.MimeType != "image/x-custom" {
+		t.Fatalf("expected mime type given to NewFileData to be kept")
+	}
+	fd = NewFileData("image.png").WithMimeType("image/x-other")
+	fd.WithUploader(fakeQuax(t).AsUploader())
+	if fd, _ = fd.FromBytes(png); fd == nil || *fd.MimeType != "image/x-other" {
+		t.Fatalf("expected WithMimeType to override detection")
+	}
+}
+
+type fixedUploader struct {
+	url   string
+	names []string
+}
+
+func (u *fixedUploader) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	u.names = append(u.names, name)
+	return u.url, nil
+}
+
+func Test_Uploader(t *testing.T) {
+	space := &fixedUploader{url: "https://space.example/a.png"}
+	fd, err := NewHfs[any, any]("test").WithUploader(space).NewFileData("a.png").FromBytes([]byte("a"))
+	if err != nil {
+		t.Fatalf("FromBytes returned error: %v", err)
+	}
+	if fd.URL != space.url || len(space.names) != 1 {
+		t.Fatalf("expected space uploader to be used, got %q", fd.URL)
+	}
+
+	def := &fixedUploader{url: "https://default.example/b.bin"}
+	SetDefaultUploader(def)
+	defer SetDefaultUploader(nil)
+	fd, err = NewFileData("").FromReader(context.Background(), strings.NewReader("b"), "b.bin")
+	if err != nil {
+		t.Fatalf("FromReader returned error: %v", err)
+	}
+	if fd.URL != def.url || len(def.names) != 1 || def.names[0] != "b.bin" {
+		t.Fatalf("expected default uploader to be used, got %q", fd.URL)
+	}
+}
+
+func Test_FileDataValidate(t *testing.T) {
+	valid := func() *FileData {
+		fd, err := NewFileData("a.png", "image/png").FromUrl("https://example.com/a.png")
+		if err != nil {
+			t.Fatalf("FromUrl returned error: %v", err)
+		}
+		return fd
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate rejected a valid FileData: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		mutate func(fd *FileData)
+		want   string
+	}{
+		"no location": {func(fd *FileData) { fd.URL, fd.Path = "", "" }, "url and path are both empty"},
+		"bad url":     {func(fd *FileData) { fd.URL = "https://exa mple.com/%zz" }, "url: "},
+		"bad path":    {func(fd *FileData) { fd.Path = "://no-scheme" }, "path: "},
+		"size":        {func(fd *FileData) { fd.Size = -1 }, "size is negative"},
+		"mime":        {func(fd *FileData) { fd.WithMimeType("png") }, `mime type "png"`},
+		"meta":        {func(fd *FileData) { fd.Meta = nil }, "meta _type"},
+	} {
+		fd := valid()
+		tc.mutate(fd)
+		err := fd.Validate()
+		if !errors.Is(err, ErrInvalidFileData) || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected ErrInvalidFileData containing %q, got %v", name, tc.want, err)
+		}
+	}
+
+	fd := valid()
+	fd.Size = -1
+	fd.Meta = nil
+	if err := fd.Validate(); strings.Count(err.Error(), "\n") != 1 {
+		t.Fatalf("expected both violations to be reported, got %v", err)
+	}
+
+	if _, err := NewFileData("").FromUrl(""); !errors.Is(err, ErrInvalidFileData) {
+		t.Fatalf("expected FromUrl to validate, got %v", err)
+	}
+}
+
+func Test_DoOne(t *testing.T) {
+	hfs := newTestHfs[any, string](fakeGradio(t, "event: complete\ndata: [\"first\", \"second\"]\n\n", nil))
+	res, err := hfs.DoOne(context.Background(), test_endpoint)
+	if err != nil {
+		t.Fatalf("DoOne returned error: %v", err)
+	}
+	if res != "first" {
+		t.Fatalf("expected first output, got %q", res)
+	}
+
+	hfs = newTestHfs[any, string](fakeGradio(t, "event: complete\ndata: []\n\n", nil))
+	if res, err := hfs.DoOne(context.Background(), test_endpoint); !errors.Is(err, ErrNoData) || res != "" {
+		t.Fatalf("expected ErrNoData and zero value, got %q, %v", res, err)
+	}
+
+	hfs = newTestHfs[any, string](fakeGradio(t, "event: error\ndata: null\n\n", nil))
+	if _, err := hfs.DoOne(context.Background(), test_endpoint); !errors.Is(err, ErrEventError) {
+		t.Fatalf("expected ErrEventError, got %v", err)
+	}
+}
+
+func Test_DoWithProgress(t *testing.T) {
+	sse := "event: heartbeat\ndata: null\n\n" +
+		"event: generating\ndata: [\"a\"]\n\n" +
+		"event: generating\ndata: [\"a cat\"]\n\n" +
+		"event: complete\ndata: [\"a cat sat\"]\n\n"
+	hfs := newTestHfs[any, string](fakeGradio(t, sse, nil))
+
+	var events []string
+	res, err := hfs.DoWithProgress(context.Background(), test_endpoint, func(event, data string) {
+		events = append(events, event+" "+data)
+	}, "x")
+	if err != nil || len(res) != 1 || res[0] != "a cat sat" {
+		t.Fatalf("DoWithProgress returned %v, %v", res, err)
+	}
+	want := []string{`heartbeat null`, `generating ["a"]`, `generating ["a cat"]`}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected events %q, got %q", want, events)
+	}
+
+	if res, err := hfs.DoWithProgress(context.Background(), test_endpoint, nil, "x"); err != nil || res[0] != "a cat sat" {
+		t.Fatalf("DoWithProgress without callback returned %v, %v", res, err)
+	}
+}
+
+func Test_DoRaw(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: generating\ndata: [\"half\"]\n\nevent: complete\ndata: [\"a cat\", {\"n\": 1}]\n\n"))
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, any](srv)
+
+	res, err := hfs.Do("/predict", "cat", 2.5)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	raw, err := hfs.DoRaw(context.Background(), "/predict", []byte(bodies[0]))
+	if err != nil {
+		t.Fatalf("DoRaw returned error: %v", err)
+	}
+	if bodies[1] != bodies[0] {
+		t.Fatalf("expected the body to be sent verbatim, got %q after %q", bodies[1], bodies[0])
+	}
+	if string(raw) != `["a cat", {"n": 1}]` {
+		t.Fatalf("expected the raw data of the complete event, got %q", raw)
+	}
+	var decoded []any
+	if err := json.Unmarshal(raw, &decoded); err != nil || !reflect.DeepEqual(decoded, res) {
+		t.Fatalf("expected raw output matching Do's %v, got %v, %v", res, decoded, err)
+	}
+}
+
+func Test_Predict(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"first\", \"second\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	hfs := newTestHfs[any, string](srv)
+	res, err := hfs.Predict(context.Background(), "x")
+	if err != nil || len(res) != 2 {
+		t.Fatalf("Predict returned %v, %v", res, err)
+	}
+	one, err := hfs.PredictOne(context.Background(), "x")
+	if err != nil || one != "first" {
+		t.Fatalf("PredictOne returned %q, %v", one, err)
+	}
+	for _, p := range paths {
+		if !strings.HasPrefix(p, "/gradio_api/call/predict") {
+			t.Fatalf("expected requests to /predict, got %v", paths)
+		}
+	}
+	if paths[0] != "/gradio_api/call/predict" {
+		t.Fatalf("expected the POST to end in /predict, got %s", paths[0])
+	}
+}
+
+func Test_RateLimitNonPositive(t *testing.T) {
+	srv := fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", nil)
+	for _, rps := range []float64{0, -1} {
+		hfs := newTestHfs[any, string](srv).WithRateLimit(rps)
+		if hfs.limiter != nil {
+			t.Fatalf("expected WithRateLimit(%v) to remove the limit", rps)
+		}
+		if _, err := hfs.Do(test_endpoint); err != nil {
+			t.Fatalf("WithRateLimit(%v): Do returned error: %v", rps, err)
+		}
+	}
+
+	hfs := newTestHfs[any, string](srv).WithRateLimit(5).WithRateLimitBurst(0)
+	if b := hfs.limiter.Burst(); b != 5 {
+		t.Fatalf("expected a burst of 0 to restore the default of 5, got %d", b)
+	}
+	if _, err := hfs.Do(test_endpoint); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+}
+
+func Test_RateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, string](srv).WithRateLimit(2)
+
+	start := time.Now()
+	for range 10 {
+		if _, err := hfs.Do(test_endpoint); err != nil {
+			t.Fatalf("Do returned error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 4*time.Second {
+		t.Fatalf("expected 10 requests at 2 rps to take at least 4s, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start = time.Now()
+	_, err := hfs.WithRateLimitBurst(1).DoWithContext(ctx, test_endpoint)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled while waiting, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Fatalf("expected cancellation to return promptly, took %v", elapsed)
+	}
+}
+
+func Test_DoNamed(t *testing.T) {
+	var body map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	res, err := newTestHfs[any, string](srv).DoNamed(context.Background(), test_endpoint, map[string]any{"prompt": "cat", "seed": 42})
+	if err != nil {
+		t.Fatalf("DoNamed returned error: %v", err)
+	}
+	if len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("unexpected result %v", res)
+	}
+	if got := string(body["data"]); got != `{"prompt":"cat","seed":42}` {
+		t.Fatalf("expected data to be an object, got %s", got)
+	}
+
+	if _, err := newTestHfs[any, string](srv).DoNamed(context.Background(), test_endpoint, map[string]any{"f": func() {}}); !errors.Is(err, ErrUnserializableParam) {
+		t.Fatalf("expected ErrUnserializableParam, got %v", err)
+	}
+}
+
+func Test_DoByIndex(t *testing.T) {
+	var body map[string]json.RawMessage
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	res, err := newTestHfs[any, string](srv).DoByIndex(context.Background(), 2, "cat")
+	if err != nil {
+		t.Fatalf("DoByIndex returned error: %v", err)
+	}
+	if len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("unexpected result %v", res)
+	}
+	if got := string(body["fn_index"]); got != "2" {
+		t.Fatalf("expected fn_index 2 in the body, got %s", got)
+	}
+	if got := string(body["data"]); got != `["cat"]` {
+		t.Fatalf("expected positional data, got %s", got)
+	}
+	want := []string{"POST /gradio_api/call", "GET /gradio_api/call/evt"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected requests %v, got %v", want, paths)
+	}
+}
+
+func Test_WithFnIndex(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	if _, err := newTestHfs[any, string](srv).Do("/predict", "cat"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if _, err := newTestHfs[any, string](srv).WithFnIndex(2).DoWithContext(context.Background(), "/predict", "cat"); err != nil {
+		t.Fatalf("DoWithContext returned error: %v", err)
+	}
+	if _, err := newTestHfs[any, string](srv).WithFnIndex(2).WithFnIndex(-1).Do("/predict", "cat"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	want := []string{`{"data":["cat"]}`, `{"data":["cat"],"fn_index":2}`, `{"data":["cat"]}`}
+	if !reflect.DeepEqual(bodies, want) {
+		t.Fatalf("expected bodies %v, got %v", want, bodies)
+	}
+}
+
+type deletingUploader struct {
+	fixedUploader
+	deleted []string
+	err     error
+}
+
+func (u *deletingUploader) Delete(ctx context.Context, fileURL string) error {
+	u.deleted = append(u.deleted, fileURL)
+	return u.err
+}
+
+func Test_FileDataDelete(t *testing.T) {
+	ctx := context.Background()
+	u := &deletingUploader{fixedUploader: fixedUploader{url: "https://files.example/a.png"}}
+	fd, err := NewFileData("a.png").WithUploader(u).FromBytes([]byte("a"))
+	if err != nil {
+		t.Fatalf("FromBytes returned error: %v", err)
+	}
+	if err := fd.Delete(ctx); err != nil {
+		t.Fatalf("Delete() returned error: %v", err)
+	}
+	if len(u.deleted) != 1 || u.deleted[0] != u.url {
+		t.Fatalf("expected the uploader to delete %s, got %v", u.url, u.deleted)
+	}
+
+	u.err = errors.New("gone")
+	DeferDelete(ctx, fd)
+	if len(u.deleted) != 2 {
+		t.Fatalf("expected DeferDelete to call the uploader")
+	}
+
+	fd, err = NewFileData("a.png").WithUploader(fakeQuax(t).AsUploader()).FromBytes([]byte("a"))
+	if err != nil {
+		t.Fatalf("FromBytes returned error: %v", err)
+	}
+	if err := fd.Delete(ctx); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported from Quax, got %v", err)
+	}
+
+	fd, _ = NewFileData("").FromUrl("https://example.com/a.png")
+	if err := fd.Delete(ctx); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported for a file that was not uploaded, got %v", err)
+	}
+}
+
+func Test_RequestID(t *testing.T) {
+	var mu sync.Mutex
+	ids := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids[r.Method] = r.Header.Get("X-Request-ID")
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/fail/evt") {
+			w.Write([]byte("event: error\ndata: null\n\n"))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	if _, err := newTestHfs[any, string](srv).WithRequestID(nil).Do(test_endpoint); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if len(ids["POST"]) != 36 || ids["GET"] != ids["POST"] {
+		t.Fatalf("expected POST and GET to carry the same UUID, got %q and %q", ids["POST"], ids["GET"])
+	}
+	first := ids["POST"]
+
+	var n atomic.Int32
+	hfs := newTestHfs[any, string](srv).WithRequestID(func() string { return fmt.Sprintf("req-%d", n.Add(1)) })
+	if _, err := hfs.Do(test_endpoint); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if ids["POST"] != "req-1" || ids["GET"] != "req-1" || first == "req-1" {
+		t.Fatalf("expected the generator's ID on POST and GET, got %q and %q", ids["POST"], ids["GET"])
+	}
+
+	_, err := hfs.Do("/fail")
+	if err == nil || !strings.Contains(err.Error(), "req-2") || !errors.Is(err, ErrEventError) {
+		t.Fatalf("expected the request ID in the event error, got %v", err)
+	}
+}
+
+func Test_DoFile(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"event_id":"evt"}`))
+		case strings.HasPrefix(r.URL.Path, "/gradio_api/call/"):
+			fmt.Fprintf(w, "event: complete\ndata: [{\"path\": \"out.png\", \"url\": \"%s/file=out.png\", \"orig_name\": \"out.png\"}]\n\n", srv.URL)
+		default:
+			w.Write([]byte("png bytes"))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "images", "cat.png")
+	if err := newTestHfs[any, any](srv).DoFile(ctx, test_endpoint, path, "a cat"); err != nil {
+		t.Fatalf("DoFile returned error: %v", err)
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != "png bytes" {
+		t.Fatalf("expected the output file at %s, got %q, %v", path, got, err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() returned error: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = newTestHfs[any, *FileData](srv).DoFile(ctx, test_endpoint, "-", "a cat")
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatalf("DoFile to stdout returned error: %v", err)
+	}
+	if got, _ := io.ReadAll(r); string(got) != "png bytes" {
+		t.Fatalf("expected the file on stdout, got %q", got)
+	}
+
+	text := fakeGradio(t, "event: complete\ndata: [\"just text\"]\n\n", nil)
+	if err := newTestHfs[any, any](text).DoFile(ctx, test_endpoint, path); !errors.Is(err, ErrDecodeFailure) {
+		t.Fatalf("expected ErrDecodeFailure for a non-file output, got %v", err)
+	}
+}
+
+func Test_ResponseSizeLimit(t *testing.T) {
+	padding := strings.Repeat(" ", 200)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/bigpost"):
+			w.Write([]byte(`{"event_id":"evt"` + padding + `}`))
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"event_id":"evt"}`))
+		case strings.HasSuffix(r.URL.Path, "/bigget/evt"):
+			w.Write([]byte("event: complete\ndata: [\"" + strings.Repeat("x", 200) + "\"]\n\n"))
+		default:
+			w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+		}
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, string](srv).WithResponseSizeLimit(100)
+
+	if res, err := hfs.Do("/small"); err != nil || res[0] != "ok" {
+		t.Fatalf("expected a response under the limit to pass, got %v, %v", res, err)
+	}
+	for _, endpoint := range []string{"/bigpost", "/bigget"} {
+		if _, err := hfs.Do(endpoint); !errors.Is(err, ErrResponseTooLarge) {
+			t.Fatalf("%s: expected ErrResponseTooLarge, got %v", endpoint, err)
+		}
+	}
+
+	exact := &limitedBody{ReadCloser: io.NopCloser(strings.NewReader(padding)), limit: 200, left: 200}
+	if got, err := io.ReadAll(exact); err != nil || len(got) != 200 {
+		t.Fatalf("expected a body of exactly the limit to pass, got %d bytes, %v", len(got), err)
+	}
+}
+
+func Test_FileDataReader(t *testing.T) {
+	content := bytes.Repeat([]byte("video"), 100000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hf_x" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	fd, _ := NewFileData("").FromUrl(srv.URL)
+	if _, err := fd.Reader(context.Background()); err == nil {
+		t.Fatalf("expected unauthenticated Reader() to fail")
+	}
+
+	rc, err := FileDataReader(fd, &http.Client{Timeout: 5 * time.Second}, map[string]string{"Authorization": "Bearer hf_x"})
+	if err != nil {
+		t.Fatalf("FileDataReader() returned error: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("expected the full content, got %d bytes, %v", len(got), err)
+	}
+
+	rc, err = fd.WithAuthHeader("Authorization", "Bearer hf_x").Reader(context.Background())
+	if err != nil {
+		t.Fatalf("Reader() returned error: %v", err)
+	}
+	defer rc.Close()
+	if n, err := io.Copy(io.Discard, rc); err != nil || n != int64(len(content)) {
+		t.Fatalf("expected %d bytes, got %d, %v", len(content), n, err)
+	}
+
+	if _, err := NewFileData("").Reader(context.Background()); err == nil {
+		t.Fatalf("expected error for an empty URL")
 	}
 }
@@ -0,0 +1,89 @@
+package hfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// HTTPCache stores raw event stream responses keyed by the POST request that produced them.
+// Use HTTPCacheKey() to derive a key from the request.
+type HTTPCache interface {
+	Get(req *http.Request) (*http.Response, bool)
+	Set(req *http.Request, resp *http.Response)
+}
+
+// HTTPCacheKey returns the request URL plus a SHA-256 of its body.
+func HTTPCacheKey(req *http.Request) (string, error) {
+	h := sha256.New()
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("hfs cache key body: %w", err)
+		}
+		defer body.Close()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", fmt.Errorf("hfs cache key body: %w", err)
+		}
+	}
+	return req.URL.String() + "#" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// MemoryHTTPCache is an unbounded in-memory HTTPCache, safe for concurrent use.
+type MemoryHTTPCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+func NewMemoryHTTPCache() *MemoryHTTPCache {
+	return &MemoryHTTPCache{entries: map[string]cachedResponse{}}
+}
+
+func (c *MemoryHTTPCache) Get(req *http.Request) (*http.Response, bool) {
+	key, err := HTTPCacheKey(req)
+	if err != nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.statusCode, http.StatusText(e.statusCode)),
+		StatusCode:    e.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}, true
+}
+
+func (c *MemoryHTTPCache) Set(req *http.Request, resp *http.Response) {
+	key, err := HTTPCacheKey(req)
+	if err != nil {
+		return
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.entries[key] = cachedResponse{statusCode: resp.StatusCode, header: resp.Header.Clone(), body: body}
+	c.mu.Unlock()
+}
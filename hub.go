@@ -0,0 +1,47 @@
+package hfs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// HubURL is the Hugging Face Hub that hf:// URLs resolve against.
+const HubURL = "https://huggingface.co"
+
+// resolveHubURL turns "hf://owner/repo[@revision]/path/to/file" into the Hub download URL
+// "https://huggingface.co/owner/repo/resolve/<revision or main>/path/to/file".
+// Dataset and space repos are written "hf://datasets/owner/repo/..." and "hf://spaces/owner/repo/...".
+func resolveHubURL(raw string) (string, error) {
+	rest, ok := strings.CutPrefix(raw, "hf://")
+	if !ok {
+		return "", fmt.Errorf("hfs hub url %q must start with hf://", raw)
+	}
+	parts := strings.Split(rest, "/")
+	kind := ""
+	if len(parts) > 0 && (parts[0] == "datasets" || parts[0] == "spaces" || parts[0] == "models") {
+		kind, parts = parts[0], parts[1:]
+	}
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[len(parts)-1] == "" {
+		return "", fmt.Errorf("hfs hub url %q must be hf://owner/repo[@revision]/path", raw)
+	}
+
+	owner, repo, file := parts[0], parts[1], parts[2:]
+	revision := "main"
+	if r, rev, ok := strings.Cut(repo, "@"); ok {
+		if r == "" || rev == "" {
+			return "", fmt.Errorf("hfs hub url %q has an empty repo or revision", raw)
+		}
+		repo, revision = r, rev
+	}
+
+	segments := []string{HubURL}
+	if kind == "datasets" || kind == "spaces" { // models have no prefix on the Hub
+		segments = append(segments, kind)
+	}
+	segments = append(segments, url.PathEscape(owner), url.PathEscape(repo), "resolve", url.PathEscape(revision))
+	for _, f := range file {
+		segments = append(segments, url.PathEscape(f))
+	}
+	return strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,60 @@
+package hfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_ResolveHubURL(t *testing.T) {
+	for _, tc := range []struct {
+		raw, want string
+	}{
+		{"hf://owner/repo/file.png", "https://huggingface.co/owner/repo/resolve/main/file.png"},
+		{"hf://owner/repo@v1.0/dir/file.png", "https://huggingface.co/owner/repo/resolve/v1.0/dir/file.png"},
+		{"hf://datasets/owner/repo@abc123/data/a b.wav", "https://huggingface.co/datasets/owner/repo/resolve/abc123/data/a%20b.wav"},
+		{"hf://models/owner/repo/config.json", "https://huggingface.co/owner/repo/resolve/main/config.json"},
+	} {
+		got, err := resolveHubURL(tc.raw)
+		if err != nil || got != tc.want {
+			t.Errorf("resolveHubURL(%q) = %q, %v, want %q", tc.raw, got, err, tc.want)
+		}
+	}
+
+	for _, raw := range []string{"hf://owner/repo", "hf://owner/repo/", "hf://owner//file", "hf://owner/repo@/file", "https://owner/repo/file"} {
+		if _, err := resolveHubURL(raw); err == nil {
+			t.Errorf("expected resolveHubURL(%q) to fail", raw)
+		}
+	}
+}
+
+func Test_FromUrlHub(t *testing.T) {
+	fd, err := NewFileData("").FromUrl("hf://owner/repo@dev/cat.png")
+	if err != nil {
+		t.Fatalf("FromUrl returned error: %v", err)
+	}
+	if fd.URL != "https://huggingface.co/owner/repo/resolve/dev/cat.png" || fd.Path != fd.URL {
+		t.Fatalf("unexpected URL %q, path %q", fd.URL, fd.Path)
+	}
+	if len(fd.authHeaders) != 0 {
+		t.Fatalf("expected no auth without a token, got %v", fd.authHeaders)
+	}
+
+	fd, _ = NewFileData("").WithHubToken("hf_x").FromUrl("hf://owner/repo/cat.png")
+	if fd.authHeaders["Authorization"] != "Bearer hf_x" {
+		t.Fatalf("expected the hub token on the download, got %v", fd.authHeaders)
+	}
+
+	space := NewHfs[any, any]("test").WithBearerToken("hf_space")
+	fd, _ = space.NewFileData("").FromUrl("hf://owner/repo/cat.png")
+	if fd.authHeaders["Authorization"] != "Bearer hf_space" {
+		t.Fatalf("expected the space token on the download, got %v", fd.authHeaders)
+	}
+	fd, _ = space.NewFileData("").FromUrl("https://example.com/cat.png")
+	if len(fd.authHeaders) != 0 {
+		t.Fatalf("expected no token for other hosts, got %v", fd.authHeaders)
+	}
+
+	if _, err := NewFileData("").FromUrl("hf://owner"); !errors.Is(err, ErrInvalidFileData) {
+		t.Fatalf("expected ErrInvalidFileData, got %v", err)
+	}
+}
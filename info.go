@@ -0,0 +1,204 @@
+package hfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SpaceInfo describes the endpoints of a space, as served by Gradio at /gradio_api/info.
+type SpaceInfo struct {
+	NamedEndpoints map[string]EndpointInfo `json:"named_endpoints"`
+	// UnnamedEndpoints are the endpoints only reachable by function index, ordered by FnIndex.
+	UnnamedEndpoints []EndpointInfo `json:"unnamed_endpoints"`
+}
+
+// EndpointInfo describes the input and output components of one endpoint.
+type EndpointInfo struct {
+	Parameters []ParameterInfo `json:"parameters"`
+	Returns    []ReturnInfo    `json:"returns"`
+	// FnIndex is the function index of an unnamed endpoint. Gradio does not report it for named ones.
+	FnIndex int `json:"-"`
+}
+
+// ParameterInfo describes one input of an endpoint.
+type ParameterInfo struct {
+	Label         string          `json:"label"`
+	ParameterName string          `json:"parameter_name"`
+	HasDefault    bool            `json:"parameter_has_default"`
+	Default       any             `json:"parameter_default"`
+	Component     string          `json:"component"` // e.g. "Textbox", "Slider", "Image"
+	PythonType    PythonType      `json:"python_type"`
+	Type          json.RawMessage `json:"type"` // JSON Schema of the value
+	ExampleInput  any             `json:"example_input"`
+}
+
+// ReturnInfo describes one output of an endpoint.
+type ReturnInfo struct {
+	Label      string          `json:"label"`
+	Component  string          `json:"component"`
+	PythonType PythonType      `json:"python_type"`
+	Type       json.RawMessage `json:"type"`
+}
+
+// PythonType is the Python type hint Gradio reports for a component.
+type PythonType struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// UnmarshalJSON accepts unnamed endpoints both as Gradio sends them, an object keyed by function index,
+// and as the array SpaceInfo marshals to.
+func (si *SpaceInfo) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		NamedEndpoints   map[string]EndpointInfo `json:"named_endpoints"`
+		UnnamedEndpoints json.RawMessage         `json:"unnamed_endpoints"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	si.NamedEndpoints = raw.NamedEndpoints
+	si.UnnamedEndpoints = nil
+
+	unnamed := strings.TrimSpace(string(raw.UnnamedEndpoints))
+	if unnamed == "" || unnamed == "null" {
+		return nil
+	}
+	if strings.HasPrefix(unnamed, "[") {
+		return json.Unmarshal(raw.UnnamedEndpoints, &si.UnnamedEndpoints)
+	}
+
+	var byIndex map[string]EndpointInfo
+	if err := json.Unmarshal(raw.UnnamedEndpoints, &byIndex); err != nil {
+		return err
+	}
+	for key, ep := range byIndex {
+		idx, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("unnamed endpoint key %q is not a function index", key)
+		}
+		ep.FnIndex = idx
+		si.UnnamedEndpoints = append(si.UnnamedEndpoints, ep)
+	}
+	sort.Slice(si.UnnamedEndpoints, func(i, j int) bool {
+		return si.UnnamedEndpoints[i].FnIndex < si.UnnamedEndpoints[j].FnIndex
+	})
+	return nil
+}
+
+// Info fetches the description of the space's endpoints.
+func (h *HFSpace[I, O]) Info() (*SpaceInfo, error) {
+	return h.InfoWithContext(context.Background())
+}
+
+// InfoWithContext is Info() with a context.
+func (h *HFSpace[I, O]) InfoWithContext(ctx context.Context) (*SpaceInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", h.infoURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("hfs info req create: %w", err)
+	}
+	h.applyHeaders(req.Header)
+
+	resp, err := h.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("hfs info req exec: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return nil, fmt.Errorf("hfs info resp: %w", err)
+	}
+
+	var info SpaceInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("hfs info decode: %w", err))
+	}
+	return &info, nil
+}
+
+// APISchema is a condensed form of SpaceInfo, listing the components of each named endpoint.
+type APISchema struct {
+	NamedEndpoints map[string]EndpointSchema
+}
+
+// EndpointSchema describes the input and output components of one endpoint.
+type EndpointSchema struct {
+	Inputs  []ComponentSchema
+	Outputs []ComponentSchema
+}
+
+// ComponentSchema describes one input or output component.
+type ComponentSchema struct {
+	Label      string
+	Type       string // the component, e.g. "Textbox" or "Image"
+	PythonType string // e.g. "str" or "filepath"
+	// SerializedType is the JSON type the value is sent as, e.g. "string" or "object",
+	// or empty if its JSON Schema has no single type.
+	SerializedType string
+}
+
+// Schema fetches the schema of the space's API through InfoWithContext() on its first
+// successful call, then returns the same cached value without touching the network.
+// Clones start without a cached schema.
+func (h *HFSpace[I, O]) Schema(ctx context.Context) (*APISchema, error) {
+	if schema := h.apiSchema.Load(); schema != nil {
+		return schema, nil
+	}
+	info, err := h.InfoWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schema := &APISchema{NamedEndpoints: make(map[string]EndpointSchema, len(info.NamedEndpoints))}
+	for name, ep := range info.NamedEndpoints {
+		var es EndpointSchema
+		for _, p := range ep.Parameters {
+			es.Inputs = append(es.Inputs, componentSchema(p.Label, p.Component, p.PythonType, p.Type))
+		}
+		for _, r := range ep.Returns {
+			es.Outputs = append(es.Outputs, componentSchema(r.Label, r.Component, r.PythonType, r.Type))
+		}
+		schema.NamedEndpoints[name] = es
+	}
+	h.apiSchema.CompareAndSwap(nil, schema)
+	return h.apiSchema.Load(), nil
+}
+
+// SchemaFor returns the schema of the named endpoint, e.g. "/predict", fetching the
+// API schema with Schema() if needed. It fails with ErrEndpointNotFound if the space has no such endpoint.
+func (h *HFSpace[I, O]) SchemaFor(endpoint string) (*EndpointSchema, error) {
+	schema, err := h.Schema(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	name := "/" + strings.TrimLeft(endpoint, "/")
+	es, ok := schema.NamedEndpoints[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEndpointNotFound, name)
+	}
+	return &es, nil
+}
+
+func componentSchema(label, component string, pythonType PythonType, typ json.RawMessage) ComponentSchema {
+	var jsonType struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(typ, &jsonType) // a type list or none leaves it empty
+	return ComponentSchema{
+		Label:          label,
+		Type:           component,
+		PythonType:     pythonType.Type,
+		SerializedType: jsonType.Type,
+	}
+}
+
+// infoURL turns a BaseURL like ".../gradio_api/call" into ".../gradio_api/info".
+// The synchronous APIs serve it at the root.
+func (h *HFSpace[I, O]) infoURL() string {
+	if h.syncAPI() {
+		return h.rootURL() + "info"
+	}
+	return strings.TrimSuffix(h.BaseURL, "/call") + "/info"
+}
@@ -0,0 +1,99 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_Info(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/info.json")
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	info, err := newTestHfs[any, any](srv).Info()
+	if err != nil {
+		t.Fatalf("Info() returned error: %v", err)
+	}
+	if gotPath != "/gradio_api/info" {
+		t.Fatalf("expected GET /gradio_api/info, got %s", gotPath)
+	}
+
+	ep, ok := info.NamedEndpoints["/infer"]
+	if !ok {
+		t.Fatalf("expected named endpoint /infer, got %v", info.NamedEndpoints)
+	}
+	if len(ep.Parameters) != 4 || len(ep.Returns) != 2 {
+		t.Fatalf("expected 4 parameters and 2 returns, got %d and %d", len(ep.Parameters), len(ep.Returns))
+	}
+	seed := ep.Parameters[2]
+	if seed.ParameterName != "seed" || seed.Component != "Slider" || !seed.HasDefault || seed.PythonType.Type != "float" {
+		t.Fatalf("unexpected seed parameter: %+v", seed)
+	}
+	if ep.Returns[0].Component != "Image" || ep.Returns[0].PythonType.Type != "filepath" {
+		t.Fatalf("unexpected first return: %+v", ep.Returns[0])
+	}
+
+	if len(info.UnnamedEndpoints) != 2 {
+		t.Fatalf("expected 2 unnamed endpoints, got %d", len(info.UnnamedEndpoints))
+	}
+	if info.UnnamedEndpoints[0].FnIndex != 1 || info.UnnamedEndpoints[1].FnIndex != 3 {
+		t.Fatalf("expected unnamed endpoints ordered by fn index, got %d and %d",
+			info.UnnamedEndpoints[0].FnIndex, info.UnnamedEndpoints[1].FnIndex)
+	}
+	if len(info.UnnamedEndpoints[0].Parameters) != 1 || len(info.UnnamedEndpoints[1].Returns) != 1 {
+		t.Fatalf("unexpected unnamed endpoints: %+v", info.UnnamedEndpoints)
+	}
+}
+
+func Test_Schema(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/info.json")
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	var gets atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets.Add(1)
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, any](srv)
+
+	schema, err := hfs.Schema(context.Background())
+	if err != nil {
+		t.Fatalf("Schema() returned error: %v", err)
+	}
+	ep, ok := schema.NamedEndpoints["/infer"]
+	if !ok || len(ep.Inputs) != 4 || len(ep.Outputs) != 2 {
+		t.Fatalf("unexpected /infer schema: %+v", ep)
+	}
+	want := ComponentSchema{Label: "Prompt", Type: "Textbox", PythonType: "str", SerializedType: "string"}
+	if ep.Inputs[1] != want {
+		t.Fatalf("expected %+v, got %+v", want, ep.Inputs[1])
+	}
+	if ep.Outputs[0].SerializedType != "object" || ep.Outputs[0].PythonType != "filepath" {
+		t.Fatalf("unexpected first output: %+v", ep.Outputs[0])
+	}
+
+	infer, err := hfs.SchemaFor("infer")
+	if err != nil || infer.Inputs[2].Label != "Seed" {
+		t.Fatalf("SchemaFor() returned %+v, %v", infer, err)
+	}
+	if _, err := hfs.SchemaFor("/missing"); !errors.Is(err, ErrEndpointNotFound) {
+		t.Fatalf("expected ErrEndpointNotFound, got %v", err)
+	}
+	if n := gets.Load(); n != 1 {
+		t.Fatalf("expected the schema to be fetched once, got %d requests", n)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build integration
+
+// Tests against a live space. Run with: go test -tags integration
+// The same calls run against fixtures in Test_ReplayFileDataFromURL and Test_ReplayFileDataFromBytes.
+package hfs
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// liveClient returns a client for the live space, which can take minutes to answer.
+func liveClient() *http.Client {
+	client := newHTTPClient()
+	client.Timeout = 300 * time.Second
+	return client
+}
+
+func Test_FileDataFromURL(t *testing.T) {
+	t.Parallel()
+	fileDataFromURL(t, liveClient())
+}
+
+func Test_FileDataFromBytes(t *testing.T) {
+	t.Parallel()
+	fileDataFromBytes(t, liveClient())
+}
@@ -0,0 +1,39 @@
+package hfs
+
+import (
+	"fmt"
+	"io"
+)
+
+// limitBody caps body at the WithResponseSizeLimit limit, if any.
+func (c *config) limitBody(body io.ReadCloser) io.ReadCloser {
+	if c.responseLimit <= 0 {
+		return body
+	}
+	return &limitedBody{ReadCloser: body, limit: c.responseLimit, left: c.responseLimit}
+}
+
+// limitedBody fails with ErrResponseTooLarge once more than limit bytes arrive,
+// so a truncated body is never mistaken for a complete one.
+type limitedBody struct {
+	io.ReadCloser
+	limit, left int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.left <= 0 {
+		// A body of exactly limit bytes is fine; only fail if there is more.
+		var probe [1]byte
+		n, err := l.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, withKind(ErrResponseTooLarge, fmt.Errorf("hfs response over %d byte limit", l.limit))
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.left {
+		p = p[:l.left]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.left -= int64(n)
+	return n, err
+}
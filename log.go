@@ -0,0 +1,102 @@
+package hfs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// phase is one step of a request ("post", "get" or "parse").
+// It is logged when it ends and, if a tracer is set, wrapped in an "hfs.<name>" span.
+type phase struct {
+	ctx      context.Context
+	logger   *slog.Logger
+	span     trace.Span
+	name     string
+	url      string
+	endpoint string
+	start    time.Time
+}
+
+// startPhase starts a phase. The returned context carries its span, if any.
+func (c *config) startPhase(ctx context.Context, name, url, endpoint string) (context.Context, phase) {
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	p := phase{logger: logger, name: name, url: url, endpoint: endpoint, start: time.Now()}
+	if c.tracer != nil {
+		ctx, p.span = c.tracer.Start(ctx, "hfs."+name)
+	}
+	p.ctx = ctx
+	return ctx, p
+}
+
+// end logs the phase at Debug level, or at Error level if err is set, and ends its span.
+// Zero status and empty eventID are left out.
+func (p phase) end(status int, eventID string, err error) {
+	if p.span != nil {
+		p.endSpan(status, eventID, err)
+	}
+
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelError
+	}
+	if !p.logger.Enabled(p.ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 7)
+	attrs = append(attrs, slog.String("phase", p.name))
+	if p.url != "" {
+		attrs = append(attrs, slog.String("url", p.url))
+	}
+	if status != 0 {
+		attrs = append(attrs, slog.Int("status_code", status))
+	}
+	attrs = append(attrs, slog.Int64("duration_ms", time.Since(p.start).Milliseconds()))
+	if eventID != "" {
+		attrs = append(attrs, slog.String("event_id", eventID))
+	}
+	if id := requestIDFrom(p.ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	p.logger.LogAttrs(p.ctx, level, "hfs "+p.name, attrs...)
+}
+
+func (p phase) endSpan(status int, eventID string, err error) {
+	defer p.span.End()
+	if !p.span.IsRecording() {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, 5)
+	if p.url != "" {
+		attrs = append(attrs, attribute.String("http.url", p.url))
+	}
+	if status != 0 {
+		attrs = append(attrs, attribute.Int("http.status_code", status))
+	}
+	if eventID != "" {
+		attrs = append(attrs, attribute.String("hfs.event_id", eventID))
+	}
+	if p.endpoint != "" {
+		attrs = append(attrs, attribute.String("hfs.endpoint", p.endpoint))
+	}
+	if id := requestIDFrom(p.ctx); id != "" {
+		attrs = append(attrs, attribute.String("hfs.request_id", id))
+	}
+	p.span.SetAttributes(attrs...)
+	if err != nil {
+		p.span.RecordError(err)
+		p.span.SetStatus(codes.Error, err.Error())
+	}
+}
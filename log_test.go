@@ -0,0 +1,142 @@
+package hfs
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingTracer records the name, attributes and status of every span it starts.
+type recordingTracer struct {
+	noop.Tracer
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	noop.Span
+	name   string
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &recordedSpan{name: name, attrs: map[attribute.Key]attribute.Value{}}
+	t.spans = append(t.spans, s)
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+func (s *recordedSpan) IsRecording() bool                   { return true }
+func (s *recordedSpan) End(...trace.SpanEndOption)          { s.ended = true }
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func Test_Tracer(t *testing.T) {
+	srv := fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", nil)
+
+	if _, err := newTestHfs[any, string](srv).WithTracer(noop.NewTracerProvider().Tracer("hfs")).Do("/predict"); err != nil {
+		t.Fatalf("Do with noop tracer returned error: %v", err)
+	}
+
+	tracer := &recordingTracer{}
+	if _, err := newTestHfs[any, string](srv).WithTracer(tracer).Do("/predict"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(tracer.spans))
+	}
+	post, get, parse := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	if post.name != "hfs.post" || get.name != "hfs.get" || parse.name != "hfs.parse" {
+		t.Fatalf("unexpected span names %q, %q, %q", post.name, get.name, parse.name)
+	}
+	if post.attrs["http.url"].AsString() != srv.URL+"/gradio_api/call/predict" ||
+		post.attrs["http.status_code"].AsInt64() != 200 ||
+		post.attrs["hfs.event_id"].AsString() != "evt" ||
+		post.attrs["hfs.endpoint"].AsString() != "/predict" {
+		t.Fatalf("unexpected post span attributes %v", post.attrs)
+	}
+	if get.attrs["http.url"].AsString() != srv.URL+"/gradio_api/call/predict/evt" {
+		t.Fatalf("unexpected get span attributes %v", get.attrs)
+	}
+	for _, s := range tracer.spans {
+		if !s.ended || s.status == codes.Error {
+			t.Fatalf("expected span %s to end without error", s.name)
+		}
+	}
+
+	tracer = &recordingTracer{}
+	failing := newTestHfs[any, string](fakeGradio(t, "event: error\ndata: null\n\n", nil)).WithTracer(tracer)
+	if _, err := failing.Do("/predict"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if last := tracer.spans[len(tracer.spans)-1]; last.name != "hfs.parse" || last.status != codes.Error {
+		t.Fatalf("expected failed parse span, got %s with status %v", last.name, last.status)
+	}
+}
+
+func Test_Logger(t *testing.T) {
+	srv := fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", nil)
+	var buf bytes.Buffer
+	hfs := newTestHfs[any, string](srv).WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if _, err := hfs.Do("/predict"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	for _, want := range []string{
+		"level=DEBUG msg=\"hfs post\" phase=post url=" + srv.URL + "/gradio_api/call/predict status_code=200 duration_ms=",
+		"phase=get url=" + srv.URL + "/gradio_api/call/predict/evt status_code=200",
+		"phase=parse duration_ms=",
+		"event_id=evt",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected log to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	hfs.BaseURL = missing.URL
+	if _, err := hfs.Do("/predict"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.Contains(buf.String(), "level=ERROR msg=\"hfs post\" phase=post") || !strings.Contains(buf.String(), "status_code=404") || !strings.Contains(buf.String(), "error=") {
+		t.Fatalf("expected error record, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	hfs.BaseURL = srv.URL + "/gradio_api/call"
+	if _, err := hfs.WithRequestID(func() string { return "req-1" }).Do("/predict"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if n := strings.Count(buf.String(), "request_id=req-1"); n != 3 {
+		t.Fatalf("expected request_id on all 3 phases, got %d:\n%s", n, buf.String())
+	}
+
+	buf.Reset()
+	quiet := newTestHfs[any, string](fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", nil)).
+		WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	if _, err := quiet.Do("/predict"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no log at info level, got:\n%s", buf.String())
+	}
+}
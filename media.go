@@ -0,0 +1,337 @@
+package hfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"time"
+)
+
+// ImageData is a FileData holding an image, with its dimensions.
+// PNG, JPEG and GIF are supported.
+type ImageData struct {
+	*FileData
+	Width, Height int
+}
+
+// AudioData is a FileData holding a WAV or MP3 file, with its duration and sample rate.
+type AudioData struct {
+	*FileData
+	Duration   time.Duration
+	SampleRate int
+}
+
+// VideoData is a FileData holding an MP4 or QuickTime video, with its dimensions and duration.
+type VideoData struct {
+	*FileData
+	Width, Height int
+	Duration      time.Duration
+}
+
+// FromBytes parses the image header of data, then uploads it like FileData.FromBytes() under name.
+// Data that is not a supported image fails before anything is uploaded.
+// The FileData's uploader and other settings are used if img already has one.
+func (img *ImageData) FromBytes(data []byte, name string) (*ImageData, error) {
+	if err := img.parse(data); err != nil {
+		return nil, err
+	}
+	fd, err := mediaFileData(img.FileData, name).FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	img.FileData = fd
+	return img, nil
+}
+
+// Probe downloads the file, e.g. an output of a space, and parses its image header.
+func (img *ImageData) Probe(ctx context.Context) error {
+	data, err := probeData(ctx, img.FileData)
+	if err != nil {
+		return err
+	}
+	return img.parse(data)
+}
+
+func (img *ImageData) parse(data []byte) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return withKind(ErrDecodeFailure, fmt.Errorf("hfs image header: %w", err))
+	}
+	img.Width, img.Height = cfg.Width, cfg.Height
+	return nil
+}
+
+// FromBytes parses the audio header of data, then uploads it like FileData.FromBytes() under name.
+// Data that is not a supported audio file fails before anything is uploaded.
+// The FileData's uploader and other settings are used if a already has one.
+func (a *AudioData) FromBytes(data []byte, name string) (*AudioData, error) {
+	if err := a.parse(data); err != nil {
+		return nil, err
+	}
+	fd, err := mediaFileData(a.FileData, name).FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	a.FileData = fd
+	return a, nil
+}
+
+// Probe downloads the file, e.g. an output of a space, and parses its audio header.
+func (a *AudioData) Probe(ctx context.Context) error {
+	data, err := probeData(ctx, a.FileData)
+	if err != nil {
+		return err
+	}
+	return a.parse(data)
+}
+
+func (a *AudioData) parse(data []byte) error {
+	var err error
+	switch {
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		a.Duration, a.SampleRate, err = parseWAV(data)
+	default:
+		a.Duration, a.SampleRate, err = parseMP3(data)
+	}
+	if err != nil {
+		return withKind(ErrDecodeFailure, fmt.Errorf("hfs audio header: %w", err))
+	}
+	return nil
+}
+
+// FromBytes parses the video header of data, then uploads it like FileData.FromBytes() under name.
+// Data that is not a supported video fails before anything is uploaded.
+// The FileData's uploader and other settings are used if v already has one.
+func (v *VideoData) FromBytes(data []byte, name string) (*VideoData, error) {
+	if err := v.parse(data); err != nil {
+		return nil, err
+	}
+	fd, err := mediaFileData(v.FileData, name).FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	v.FileData = fd
+	return v, nil
+}
+
+// Probe downloads the file, e.g. an output of a space, and parses its video header.
+func (v *VideoData) Probe(ctx context.Context) error {
+	data, err := probeData(ctx, v.FileData)
+	if err != nil {
+		return err
+	}
+	return v.parse(data)
+}
+
+func (v *VideoData) parse(data []byte) error {
+	var err error
+	v.Width, v.Height, v.Duration, err = parseMP4(data)
+	if err != nil {
+		return withKind(ErrDecodeFailure, fmt.Errorf("hfs video header: %w", err))
+	}
+	return nil
+}
+
+// mediaFileData returns fd named name, or a new FileData if fd is nil.
+func mediaFileData(fd *FileData, name string) *FileData {
+	if fd == nil {
+		return NewFileData(name)
+	}
+	fd.OrigName = name
+	return fd
+}
+
+// probeData downloads fd for parsing its header.
+func probeData(ctx context.Context, fd *FileData) ([]byte, error) {
+	if fd == nil {
+		return nil, withKind(ErrInvalidFileData, errors.New("hfs probe: no FileData"))
+	}
+	body, err := fd.Reader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("hfs probe read: %w", err)
+	}
+	return data, nil
+}
+
+// parseWAV reads the "fmt " and "data" chunks of a RIFF WAVE file.
+func parseWAV(data []byte) (time.Duration, int, error) {
+	var sampleRate, byteRate uint32
+	for off := 12; off+8 <= len(data); {
+		id := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		body := data[off+8:]
+		switch id {
+		case "fmt ":
+			if len(body) < 12 {
+				return 0, 0, errors.New("wav fmt chunk too short")
+			}
+			sampleRate = binary.LittleEndian.Uint32(body[4:8])
+			byteRate = binary.LittleEndian.Uint32(body[8:12])
+		case "data":
+			if byteRate == 0 {
+				return 0, 0, errors.New("wav data chunk before fmt chunk")
+			}
+			if size > len(body) {
+				size = len(body) // truncated, or a streamed file with a placeholder size
+			}
+			return time.Duration(int64(size) * int64(time.Second) / int64(byteRate)), int(sampleRate), nil
+		}
+		off += 8 + size + size%2 // chunks are padded to an even size
+	}
+	return 0, 0, errors.New("wav data chunk not found")
+}
+
+var (
+	mp3Bitrates = [2][16]int{ // kbps of Layer III, by MPEG-1 and MPEG-2/2.5
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	}
+	mp3SampleRates = [3][3]int{ // by MPEG-1, 2 and 2.5
+		{44100, 48000, 32000},
+		{22050, 24000, 16000},
+		{11025, 12000, 8000},
+	}
+)
+
+// parseMP3 reads the first Layer III frame header after any ID3v2 tag. The duration comes from
+// the frame count of a Xing/Info header if present, otherwise from the bitrate of the first frame.
+func parseMP3(data []byte) (time.Duration, int, error) {
+	off := 0
+	if len(data) >= 10 && string(data[:3]) == "ID3" {
+		size := int(data[6]&0x7f)<<21 | int(data[7]&0x7f)<<14 | int(data[8]&0x7f)<<7 | int(data[9]&0x7f)
+		off = 10 + size
+	}
+	for ; off+4 <= len(data); off++ {
+		if data[off] != 0xff || data[off+1]&0xe0 != 0xe0 {
+			continue
+		}
+		version := (data[off+1] >> 3) & 3 // 0: MPEG-2.5, 2: MPEG-2, 3: MPEG-1
+		layer := (data[off+1] >> 1) & 3   // 1: Layer III
+		bitrateIdx := data[off+2] >> 4
+		rateIdx := (data[off+2] >> 2) & 3
+		if version == 1 || layer != 1 || bitrateIdx == 0 || bitrateIdx == 15 || rateIdx == 3 {
+			continue
+		}
+
+		var sampleRate, bitrate, samplesPerFrame int
+		switch version {
+		case 3:
+			sampleRate, bitrate, samplesPerFrame = mp3SampleRates[0][rateIdx], mp3Bitrates[0][bitrateIdx], 1152
+		case 2:
+			sampleRate, bitrate, samplesPerFrame = mp3SampleRates[1][rateIdx], mp3Bitrates[1][bitrateIdx], 576
+		default:
+			sampleRate, bitrate, samplesPerFrame = mp3SampleRates[2][rateIdx], mp3Bitrates[1][bitrateIdx], 576
+		}
+
+		// The Xing/Info header follows the side information, whose size depends on version and channels.
+		side := 17
+		if version == 3 {
+			side = 32
+		}
+		if data[off+3]>>6 == 3 { // mono
+			side = 9
+			if version == 3 {
+				side = 17
+			}
+		}
+		if x := off + 4 + side; x+12 <= len(data) {
+			tag := string(data[x : x+4])
+			if (tag == "Xing" || tag == "Info") && data[x+7]&1 != 0 {
+				frames := int64(binary.BigEndian.Uint32(data[x+8 : x+12]))
+				return time.Duration(frames * int64(samplesPerFrame) * int64(time.Second) / int64(sampleRate)), sampleRate, nil
+			}
+		}
+		n := int64(len(data) - off)
+		return time.Duration(n * 8 * int64(time.Second) / int64(bitrate*1000)), sampleRate, nil
+	}
+	return 0, 0, errors.New("no wav or mp3 header found")
+}
+
+// parseMP4 reads the duration from the "mvhd" box and the dimensions from the "tkhd" box
+// of the first visual track of an ISO base media (MP4, QuickTime) file.
+func parseMP4(data []byte) (width, height int, duration time.Duration, err error) {
+	moov, ok := findBox(data, "moov")
+	if !ok {
+		return 0, 0, 0, errors.New("mp4 moov box not found")
+	}
+	mvhd, ok := findBox(moov, "mvhd")
+	if !ok || len(mvhd) < 20 {
+		return 0, 0, 0, errors.New("mp4 mvhd box not found")
+	}
+	var timescale, units uint64
+	if mvhd[0] == 1 { // version 1 has 64-bit times
+		if len(mvhd) < 32 {
+			return 0, 0, 0, errors.New("mp4 mvhd box too short")
+		}
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+		units = binary.BigEndian.Uint64(mvhd[24:32])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+		units = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	}
+	if timescale == 0 {
+		return 0, 0, 0, errors.New("mp4 mvhd timescale is zero")
+	}
+	duration = time.Duration(units * uint64(time.Second) / timescale)
+
+	for rest := moov; ; {
+		trak, next, ok := nextBox(rest, "trak")
+		if !ok {
+			return 0, 0, 0, errors.New("mp4 video track not found")
+		}
+		rest = next
+		tkhd, ok := findBox(trak, "tkhd")
+		if !ok || len(tkhd) < 84 {
+			continue
+		}
+		dims := tkhd[len(tkhd)-8:] // width and height, 16.16 fixed point, at the end of every tkhd version
+		width = int(binary.BigEndian.Uint32(dims[0:4]) >> 16)
+		height = int(binary.BigEndian.Uint32(dims[4:8]) >> 16)
+		if width > 0 && height > 0 {
+			return width, height, duration, nil
+		}
+	}
+}
+
+// findBox returns the payload of the first box of type typ directly in data.
+func findBox(data []byte, typ string) ([]byte, bool) {
+	payload, _, ok := nextBox(data, typ)
+	return payload, ok
+}
+
+// nextBox returns the payload of the first box of type typ directly in data and what follows it.
+func nextBox(data []byte, typ string) (payload, rest []byte, ok bool) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		header := uint64(8)
+		switch size {
+		case 0: // extends to the end
+			size = uint64(len(data))
+		case 1: // 64-bit size follows the type
+			if len(data) < 16 {
+				return nil, nil, false
+			}
+			size, header = binary.BigEndian.Uint64(data[8:16]), 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return nil, nil, false
+		}
+		if string(data[4:8]) == typ {
+			return data[header:size], data[size:], true
+		}
+		data = data[size:]
+	}
+	return nil, nil, false
+}
@@ -0,0 +1,83 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	return data
+}
+
+func Test_ImageData(t *testing.T) {
+	u := newMemoryUploader(t)
+	img, err := (&ImageData{FileData: NewFileData("").WithUploader(u)}).FromBytes(readFixture(t, "pixel.png"), "pixel.png")
+	if err != nil {
+		t.Fatalf("FromBytes() returned error: %v", err)
+	}
+	if img.Width != 2 || img.Height != 2 {
+		t.Fatalf("expected 2x2, got %dx%d", img.Width, img.Height)
+	}
+	if img.URL != u.srv.URL+"/pixel.png" || img.OrigName != "pixel.png" {
+		t.Fatalf("expected the image to be uploaded, got %+v", img.FileData)
+	}
+
+	probed := &ImageData{FileData: img.FileData}
+	if err := probed.Probe(context.Background()); err != nil || probed.Width != 2 || probed.Height != 2 {
+		t.Fatalf("Probe() returned %dx%d, %v", probed.Width, probed.Height, err)
+	}
+
+	if _, err := new(ImageData).FromBytes([]byte("not an image"), "x.png"); !errors.Is(err, ErrDecodeFailure) {
+		t.Fatalf("expected ErrDecodeFailure, got %v", err)
+	}
+	if len(u.files) != 1 {
+		t.Fatalf("expected invalid data not to be uploaded, got %d uploads", len(u.files))
+	}
+}
+
+func Test_AudioData(t *testing.T) {
+	for _, tc := range []struct {
+		file       string
+		duration   time.Duration
+		sampleRate int
+	}{
+		{"tone.wav", 500 * time.Millisecond, 8000},
+		{"tone.mp3", 10 * 1152 * time.Second / 44100, 44100},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			a := &AudioData{FileData: NewFileData("").WithUploader(newMemoryUploader(t))}
+			if _, err := a.FromBytes(readFixture(t, tc.file), tc.file); err != nil {
+				t.Fatalf("FromBytes() returned error: %v", err)
+			}
+			if a.Duration != tc.duration || a.SampleRate != tc.sampleRate {
+				t.Fatalf("expected %v at %d Hz, got %v at %d Hz", tc.duration, tc.sampleRate, a.Duration, a.SampleRate)
+			}
+		})
+	}
+
+	if _, err := new(AudioData).FromBytes([]byte("not audio"), "x.wav"); !errors.Is(err, ErrDecodeFailure) {
+		t.Fatalf("expected ErrDecodeFailure, got %v", err)
+	}
+}
+
+func Test_VideoData(t *testing.T) {
+	v, err := (&VideoData{FileData: NewFileData("").WithUploader(newMemoryUploader(t))}).FromBytes(readFixture(t, "clip.mp4"), "clip.mp4")
+	if err != nil {
+		t.Fatalf("FromBytes() returned error: %v", err)
+	}
+	if v.Width != 320 || v.Height != 240 || v.Duration != 2500*time.Millisecond {
+		t.Fatalf("expected 320x240 for 2.5s, got %dx%d for %v", v.Width, v.Height, v.Duration)
+	}
+
+	if _, err := new(VideoData).FromBytes(readFixture(t, "pixel.png"), "x.mp4"); !errors.Is(err, ErrDecodeFailure) {
+		t.Fatalf("expected ErrDecodeFailure, got %v", err)
+	}
+}
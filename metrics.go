@@ -0,0 +1,83 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricsRecorder receives request and upload measurements from an HFSpace.
+// The metrics sub-package implements it for Prometheus.
+type MetricsRecorder interface {
+	// ObserveRequest is called once per request with the endpoint ("/predict"),
+	// its outcome ("ok", the HTTP status code, or "error") and how long it took.
+	ObserveRequest(endpoint, status string, d time.Duration)
+	// AddUploadBytes is called with the size of every file uploaded by FileData of the space.
+	AddUploadBytes(n int64)
+}
+
+// requestStatus is the status reported to MetricsRecorder for a request ending with err.
+func requestStatus(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var herr *HTTPStatusError
+	if errors.As(err, &herr) {
+		return strconv.Itoa(herr.StatusCode)
+	}
+	return "error"
+}
+
+// observeRequest reports one request to the metrics recorder, if any.
+func (c *config) observeRequest(endpoint string, start time.Time, err error) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.ObserveRequest("/"+strings.TrimLeft(endpoint, "/"), requestStatus(err), time.Since(start))
+}
+
+// meteredUploader reports the size of every successful upload to a MetricsRecorder.
+type meteredUploader struct {
+	Uploader
+	metrics MetricsRecorder
+}
+
+func (u meteredUploader) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	url, err := u.Uploader.Upload(ctx, data, name)
+	if err == nil {
+		u.metrics.AddUploadBytes(int64(len(data)))
+	}
+	return url, err
+}
+
+func (u meteredUploader) uploadReader(ctx context.Context, r io.Reader, size int64, name string) (string, error) {
+	counter := &countingReader{r: r}
+	url, err := uploadReader(ctx, u.Uploader, counter, size, name)
+	if err == nil {
+		u.metrics.AddUploadBytes(counter.n)
+	}
+	return url, err
+}
+
+func (u meteredUploader) Delete(ctx context.Context, fileURL string) error {
+	if d, ok := u.Uploader.(Deleter); ok {
+		return d.Delete(ctx, fileURL)
+	}
+	return fmt.Errorf("hfs uploader %T cannot delete: %w", u.Uploader, ErrNotSupported)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,76 @@
+// Package metrics exports HFSpace request and upload metrics to Prometheus.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/ucukertz/hfs"
+)
+
+var _ hfs.MetricsRecorder = (*MetricsCollector)(nil)
+var _ prometheus.Collector = (*MetricsCollector)(nil)
+
+// MetricsCollector is a prometheus.Collector fed by one or more HFSpace through hfs.WithMetrics().
+//
+// It exports:
+//   - <namespace>_<subsystem>_requests_total{endpoint, status}: requests by outcome,
+//     status being "ok", the HTTP status code, or "error"
+//   - <namespace>_<subsystem>_request_duration_seconds{endpoint}: request latency
+//   - <namespace>_<subsystem>_upload_bytes_total: bytes uploaded for FileData inputs
+type MetricsCollector struct {
+	requests    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	uploadBytes prometheus.Counter
+}
+
+// NewMetricsCollector creates a MetricsCollector. With namespace "hfs" and an empty subsystem
+// the metrics are named hfs_requests_total, hfs_request_duration_seconds and hfs_upload_bytes_total.
+func NewMetricsCollector(namespace, subsystem string) *MetricsCollector {
+	return &MetricsCollector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Requests to Hugging Face Spaces by endpoint and outcome.",
+		}, []string{"endpoint", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of requests to Hugging Face Spaces, from POST to final event.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		}, []string{"endpoint"}),
+		uploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "upload_bytes_total",
+			Help:      "Bytes uploaded for FileData inputs.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.uploadBytes.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.uploadBytes.Collect(ch)
+}
+
+// ObserveRequest implements hfs.MetricsRecorder.
+func (c *MetricsCollector) ObserveRequest(endpoint, status string, d time.Duration) {
+	c.requests.WithLabelValues(endpoint, status).Inc()
+	c.duration.WithLabelValues(endpoint).Observe(d.Seconds())
+}
+
+// AddUploadBytes implements hfs.MetricsRecorder.
+func (c *MetricsCollector) AddUploadBytes(n int64) {
+	c.uploadBytes.Add(float64(n))
+}
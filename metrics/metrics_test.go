@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/ucukertz/hfs"
+)
+
+type fixedUploader struct{}
+
+func (fixedUploader) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	return "https://example.com/" + name, nil
+}
+
+func Test_MetricsCollector(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	collector := NewMetricsCollector("hfs", "")
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	space, err := hfs.NewHFSpaceFromURL[any, string](srv.URL, hfs.WithMetrics(collector), hfs.WithUploader(fixedUploader{}))
+	if err != nil {
+		t.Fatalf("NewHFSpaceFromURL returned error: %v", err)
+	}
+	if _, err := space.Do("/predict"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if _, err := space.Do("fail"); err == nil {
+		t.Fatalf("expected error from failing endpoint")
+	}
+	if _, err := space.NewFileData("a.bin").FromBytes([]byte("12345")); err != nil {
+		t.Fatalf("FromBytes returned error: %v", err)
+	}
+
+	expected := `
+# HELP hfs_requests_total Requests to Hugging Face Spaces by endpoint and outcome.
+# TYPE hfs_requests_total counter
+hfs_requests_total{endpoint="/fail",status="500"} 1
+hfs_requests_total{endpoint="/predict",status="ok"} 1
+# HELP hfs_upload_bytes_total Bytes uploaded for FileData inputs.
+# TYPE hfs_upload_bytes_total counter
+hfs_upload_bytes_total 5
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "hfs_requests_total", "hfs_upload_bytes_total"); err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+	if n := testutil.CollectAndCount(collector, "hfs_request_duration_seconds"); n != 2 {
+		t.Fatalf("expected 2 duration series, got %d", n)
+	}
+}
@@ -0,0 +1,112 @@
+package hfs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Doer is the request API shared by HFSpace and MockHFSpace.
+// Depend on it instead of *HFSpace to swap in a MockHFSpace in tests.
+type Doer[I, O any] interface {
+	Do(endpoint string, params ...I) ([]O, error)
+	DoWithContext(ctx context.Context, endpoint string, params ...I) ([]O, error)
+}
+
+var _ Doer[any, any] = (*HFSpace[any, any])(nil)
+var _ Doer[any, any] = (*MockHFSpace[any, any])(nil)
+
+// MockCall is one call recorded by MockHFSpace.
+type MockCall[I any] struct {
+	Endpoint string
+	Params   []I
+}
+
+// TestingT is the part of *testing.T used by MockHFSpace.AssertCalled.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// MockHFSpace is a Doer that returns preloaded responses instead of calling a space.
+// The zero value is ready to use and it is safe for concurrent use.
+type MockHFSpace[I, O any] struct {
+	mu        sync.Mutex
+	responses map[string][]mockResponse[O]
+	calls     []MockCall[I]
+}
+
+type mockResponse[O any] struct {
+	result []O
+	err    error
+}
+
+// NewMockHFSpace creates an empty MockHFSpace.
+func NewMockHFSpace[I, O any]() *MockHFSpace[I, O] {
+	return &MockHFSpace[I, O]{}
+}
+
+// AddResponse queues a response for endpoint. Queued responses are returned in order
+// and the last one is repeated once the queue is down to it.
+// Endpoints match with or without the leading "/", like in Do().
+func (m *MockHFSpace[I, O]) AddResponse(endpoint string, result []O, err error) *MockHFSpace[I, O] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.responses == nil {
+		m.responses = map[string][]mockResponse[O]{}
+	}
+	key := strings.TrimLeft(endpoint, "/")
+	m.responses[key] = append(m.responses[key], mockResponse[O]{result, err})
+	return m
+}
+
+// Do records the call and returns the next response queued for endpoint.
+func (m *MockHFSpace[I, O]) Do(endpoint string, params ...I) ([]O, error) {
+	return m.DoWithContext(context.Background(), endpoint, params...)
+}
+
+// DoWithContext is Do() that fails with ctx's error if ctx is already done.
+func (m *MockHFSpace[I, O]) DoWithContext(ctx context.Context, endpoint string, params ...I) ([]O, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, MockCall[I]{Endpoint: endpoint, Params: slices.Clone(params)})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	key := strings.TrimLeft(endpoint, "/")
+	queue := m.responses[key]
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("hfs mock: no response registered for endpoint %q", endpoint)
+	}
+	if len(queue) > 1 {
+		m.responses[key] = queue[1:]
+	}
+	return queue[0].result, queue[0].err
+}
+
+// Calls returns every call made so far, in order.
+func (m *MockHFSpace[I, O]) Calls() []MockCall[I] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.calls)
+}
+
+// AssertCalled reports a test error unless endpoint was called exactly times times.
+func (m *MockHFSpace[I, O]) AssertCalled(t TestingT, endpoint string, times int) bool {
+	t.Helper()
+	key := strings.TrimLeft(endpoint, "/")
+	count := 0
+	for _, call := range m.Calls() {
+		if strings.TrimLeft(call.Endpoint, "/") == key {
+			count++
+		}
+	}
+	if count != times {
+		t.Errorf("hfs mock: expected %d calls to %q, got %d", times, endpoint, count)
+		return false
+	}
+	return true
+}
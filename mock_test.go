@@ -0,0 +1,72 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// summarize is an example of code under test that only depends on Doer.
+func summarize(space Doer[string, string], texts ...string) (string, error) {
+	var out []string
+	for _, text := range texts {
+		res, err := space.Do("/summarize", text)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, res...)
+	}
+	return strings.Join(out, " "), nil
+}
+
+// recordingT is a TestingT that keeps reported errors instead of failing the test.
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func Test_MockHFSpace(t *testing.T) {
+	mock := NewMockHFSpace[string, string]().
+		AddResponse("summarize", []string{"first"}, nil).
+		AddResponse("/summarize", []string{"rest"}, nil)
+
+	got, err := summarize(mock, "a", "b", "c")
+	if err != nil {
+		t.Fatalf("summarize returned error: %v", err)
+	}
+	if got != "first rest rest" {
+		t.Fatalf("unexpected result %q", got)
+	}
+	mock.AssertCalled(t, "/summarize", 3)
+	if calls := mock.Calls(); !reflect.DeepEqual(calls[1], MockCall[string]{Endpoint: "/summarize", Params: []string{"b"}}) {
+		t.Fatalf("unexpected recorded call %+v", calls[1])
+	}
+
+	rt := &recordingT{}
+	if mock.AssertCalled(rt, "/summarize", 2) || len(rt.errors) != 1 {
+		t.Fatalf("expected AssertCalled to report a count mismatch, got %v", rt.errors)
+	}
+
+	if _, err := mock.Do("/unknown"); err == nil || !strings.Contains(err.Error(), `"/unknown"`) {
+		t.Fatalf("expected error for unregistered endpoint, got %v", err)
+	}
+
+	fail := errors.New("boom")
+	mock.AddResponse("/fail", nil, fail)
+	if _, err := summarize(NewMockHFSpace[string, string]().AddResponse("/summarize", nil, fail), "a"); !errors.Is(err, fail) {
+		t.Fatalf("expected configured error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := mock.DoWithContext(ctx, "/fail"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
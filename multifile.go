@@ -0,0 +1,31 @@
+package hfs
+
+import "encoding/json"
+
+// MultiFileData is a list of files for components taking several, such as gr.Files or gr.Gallery.
+// It marshals as the JSON array of FileData objects Gradio expects, never as null.
+type MultiFileData []*FileData
+
+// NewMultiFileData creates a MultiFileData holding fds.
+func NewMultiFileData(fds ...*FileData) *MultiFileData {
+	m := MultiFileData(fds)
+	return &m
+}
+
+// Multi returns a MultiFileData holding fd followed by fds.
+func (fd *FileData) Multi(fds ...*FileData) *MultiFileData {
+	return NewMultiFileData(append([]*FileData{fd}, fds...)...)
+}
+
+// Add appends fd.
+func (m *MultiFileData) Add(fd *FileData) *MultiFileData {
+	*m = append(*m, fd)
+	return m
+}
+
+func (m MultiFileData) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]*FileData(m))
+}
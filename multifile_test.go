@@ -0,0 +1,51 @@
+package hfs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_MultiFileData(t *testing.T) {
+	a, _ := NewFileData("a.png").FromUrl("https://example.com/a.png")
+	b, _ := NewFileData("b.png").FromUrl("https://example.com/b.png")
+
+	var body struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	if _, err := newTestHfs[any, string](srv).Do(test_endpoint, a.Multi().Add(b), "caption"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	var files []map[string]any
+	if err := json.Unmarshal(body.Data[0], &files); err != nil {
+		t.Fatalf("expected the first param to be a JSON array, got %s", body.Data[0])
+	}
+	if len(files) != 2 || files[0]["url"] != a.URL || files[1]["url"] != b.URL {
+		t.Fatalf("unexpected files %v", files)
+	}
+	for _, f := range files {
+		if meta, _ := f["meta"].(map[string]any); meta["_type"] != "gradio.FileData" {
+			t.Fatalf("expected every element to be a gradio.FileData, got %v", f)
+		}
+	}
+
+	empty, err := json.Marshal(NewMultiFileData())
+	if err != nil || string(empty) != "[]" {
+		t.Fatalf("expected an empty MultiFileData to marshal as [], got %s, %v", empty, err)
+	}
+	var none MultiFileData
+	if got, _ := json.Marshal(none); string(got) != "[]" {
+		t.Fatalf("expected a nil MultiFileData to marshal as [], got %s", got)
+	}
+}
@@ -0,0 +1,581 @@
+package hfs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http2"
+	"golang.org/x/time/rate"
+)
+
+// config holds the settings of an HFSpace that do not depend on its input and output types.
+type config struct {
+	BaseURL string
+	Headers map[string]string
+	client  *http.Client
+
+	middlewares []func(http.RoundTripper) http.RoundTripper
+	mwBase      http.RoundTripper // the transport wrapped by middlewares
+
+	headerFuncs map[string]func() string
+
+	dedupWindow  time.Duration
+	dedupMaxSize int
+	eventIDField string
+	outputSchema *jsonSchema
+	schemaErr    error
+	onProgress   func(p ProgressEvent)
+	clientIDs    bool
+	transforms   []func(*http.Request) (*http.Request, error)
+	httpCache    HTTPCache
+	connReuse    bool
+	gzip         bool
+
+	generatingTimeout time.Duration
+	retry             retryPolicy
+	uploader          Uploader
+	logger            *slog.Logger
+	tracer            trace.Tracer
+	metrics           MetricsRecorder
+	limiter           *rate.Limiter
+	limiterBurst      int
+	breaker           *circuitBreaker
+	propagateAuth     bool
+	requestID         func() string
+	responseLimit     int64
+	fnIndex           int
+	endpoints         map[string]*EndpointConfig
+	endpointBase      string
+	autoWarmup        bool
+	maxQueueDepth     int
+	dump              io.Writer
+	dumpAuth          bool
+
+	// optErr is the first error of an option that cannot report it itself.
+	// Every request fails with it.
+	optErr error
+}
+
+// Option configures an HFSpace. Pass options to NewHfs() or keep them in a slice
+// to share them between spaces. Options are applied in order, so later ones win.
+type Option func(*config)
+
+// defaultConfig returns the settings of an HFSpace created without options.
+func defaultConfig() config {
+	return config{
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		client:       newHTTPClient(),
+		eventIDField: "event_id",
+		fnIndex:      -1,
+		endpointBase: EndpointBaseGradio5,
+	}
+}
+
+// newHTTPClient returns a client on a clone of http.DefaultTransport,
+// so that per-space settings never leak into http.DefaultClient or other spaces.
+func newHTTPClient() *http.Client {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		return &http.Client{Transport: t.Clone()}
+	}
+	return &http.Client{Transport: http.DefaultTransport}
+}
+
+// WithBaseURL replaces the BaseURL derived from the space name, e.g. with
+// "http://localhost:7860/gradio_api/call". Use NewHFSpaceFromURL() to have it validated.
+func WithBaseURL(rawURL string) Option {
+	return func(c *config) {
+		c.BaseURL = strings.TrimRight(rawURL, "/")
+	}
+}
+
+// WithHeader sets a custom header.
+func WithHeader(key, value string) Option {
+	return func(c *config) {
+		c.Headers[key] = value
+	}
+}
+
+// WithHeaderFunc sets a header to the value fn returns at every request, e.g. a token
+// that is refreshed before it expires. It overrides a static header of the same key.
+// fn must be safe for concurrent use.
+func WithHeaderFunc(key string, fn func() string) Option {
+	return func(c *config) {
+		c.headerFuncs = maps.Clone(c.headerFuncs)
+		if c.headerFuncs == nil {
+			c.headerFuncs = map[string]func() string{}
+		}
+		c.headerFuncs[key] = fn
+	}
+}
+
+// applyHeaders sets the static headers on header, then the ones of WithHeaderFunc.
+func (c *config) applyHeaders(header http.Header) {
+	for k, v := range c.Headers {
+		header.Set(k, v)
+	}
+	for k, fn := range c.headerFuncs {
+		header.Set(k, fn())
+	}
+}
+
+// header returns the current value of the header key, as applyHeaders would set it.
+func (c *config) header(key string) (string, bool) {
+	if fn, ok := c.headerFuncs[key]; ok {
+		return fn(), true
+	}
+	v, ok := c.Headers[key]
+	return v, ok
+}
+
+// WithBearerToken adds an Authorization Bearer token.
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithTimeout sets a custom timeout on the underlying HTTP client.
+// Applies to both POST and GET requests. The timeout is set on a copy of the client,
+// so a client shared through WithHTTPClient or Clone() is not modified.
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		client := *c.client
+		client.Timeout = d
+		c.client = &client
+	}
+}
+
+// WithUserAgent sets a custom User-Agent.
+func WithUserAgent(agent string) Option {
+	return WithHeader("User-Agent", agent)
+}
+
+// WithHTTPClient allows setting a custom http.Client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+		if len(c.middlewares) > 0 {
+			c.setTransport(client.Transport)
+		}
+	}
+}
+
+// WithProxy sends all requests through the HTTP or HTTPS proxy at rawURL.
+// The transport is cloned first, so TLS and other settings carry over and a client
+// passed to WithHTTPClient is left untouched. An empty rawURL is a no-op.
+// A malformed rawURL makes every request fail; use h.WithProxyE() to get the error upfront.
+func WithProxy(rawURL string) Option {
+	return func(c *config) {
+		if err := c.setProxy(rawURL); err != nil && c.optErr == nil {
+			c.optErr = err
+		}
+	}
+}
+
+func (c *config) setProxy(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	proxy, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("hfs proxy url parse: %w", err)
+	}
+	if proxy.Scheme == "" || proxy.Host == "" {
+		return fmt.Errorf("hfs proxy url %q must have a scheme and a host", rawURL)
+	}
+	t, err := c.cloneTransport()
+	if err != nil {
+		return fmt.Errorf("hfs proxy: %w", err)
+	}
+	t.Proxy = http.ProxyURL(proxy)
+	c.setTransport(t)
+	return nil
+}
+
+// WithTLSConfig sets the TLS configuration of the transport, e.g. to trust a corporate CA
+// or pin certificates. Like WithProxy it works on a clone of the transport, so the two compose.
+// A malformed setup makes every request fail.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *config) {
+		t, err := c.cloneTransport()
+		if err != nil {
+			if c.optErr == nil {
+				c.optErr = fmt.Errorf("hfs tls config: %w", err)
+			}
+			return
+		}
+		t.TLSClientConfig = cfg.Clone()
+		c.setTransport(t)
+	}
+}
+
+// cloneTransport returns a copy of the client's transport to modify.
+// With transport middlewares, it is the transport they wrap.
+func (c *config) cloneTransport() (*http.Transport, error) {
+	rt := c.client.Transport
+	if len(c.middlewares) > 0 {
+		rt = c.mwBase
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	switch t := rt.(type) {
+	case *http.Transport:
+		return t.Clone(), nil
+	default:
+		return nil, fmt.Errorf("transport %T is not an *http.Transport", t)
+	}
+}
+
+// setTransport installs t on a copy of the client, so a client shared through WithHTTPClient is not modified.
+// With transport middlewares, t is wrapped in them.
+func (c *config) setTransport(t http.RoundTripper) {
+	client := *c.client
+	client.Transport = t
+	if len(c.middlewares) > 0 {
+		c.mwBase = t
+		if t == nil {
+			t = http.DefaultTransport
+		}
+		for _, mw := range slices.Backward(c.middlewares) {
+			t = mw(t)
+		}
+		client.Transport = t
+	}
+	c.client = &client
+}
+
+// WithTransportMiddleware wraps the transport of the client in mw, e.g. for caching, recording
+// or refreshing credentials, keeping the client's other settings. Middlewares compose in the
+// order they are added, the first being the outermost. They keep wrapping the transport when
+// later options such as WithProxy() or WithHTTPClient() replace it; mw is then called again.
+func WithTransportMiddleware(mw func(http.RoundTripper) http.RoundTripper) Option {
+	return func(c *config) {
+		base := c.client.Transport
+		if len(c.middlewares) > 0 {
+			base = c.mwBase
+		}
+		c.middlewares = append(slices.Clip(c.middlewares), mw)
+		c.setTransport(base)
+	}
+}
+
+// WithEventIDField sets the JSON field holding the event ID in the POST response.
+// Defaults to "event_id". Useful for non-standard Gradio deployments.
+func WithEventIDField(fieldName string) Option {
+	return func(c *config) {
+		c.eventIDField = fieldName
+	}
+}
+
+// WithOutputSchema validates every result against a JSON Schema document (draft-07 subset:
+// type, required, properties, items). A mismatch makes Do() return a *SchemaValidationError.
+func WithOutputSchema(schema []byte) Option {
+	return func(c *config) {
+		c.outputSchema, c.schemaErr = parseSchema(schema)
+	}
+}
+
+// WithProgressCallback calls fn synchronously for every Gradio progress update received during Do() or DoStream().
+func WithProgressCallback(fn func(p ProgressEvent)) Option {
+	return func(c *config) {
+		c.onProgress = fn
+	}
+}
+
+// WithClientGeneratedEventIDs generates the event ID on the client and sends it in the POST body,
+// so the GET can start without waiting for the POST response.
+// Only use with deployments that honour a client-provided "event_id".
+// If the server assigns its own ID anyway, the GET is retried with it.
+func WithClientGeneratedEventIDs() Option {
+	return func(c *config) {
+		c.clientIDs = true
+	}
+}
+
+// WithRequestTransform registers fn to mutate every request right before it is sent,
+// after headers and body are set. Multiple transforms run in registration order.
+func WithRequestTransform(fn func(*http.Request) (*http.Request, error)) Option {
+	return func(c *config) {
+		c.transforms = append(c.transforms, fn)
+	}
+}
+
+// WithHTTPCacheControl replays event streams from cache for requests seen before, skipping the network.
+// Unlike a result cache it works on raw HTTP responses, so one cache can be shared by several HFSpace instances.
+// Client-generated event IDs are not used while a cache is set.
+func WithHTTPCacheControl(cache HTTPCache) Option {
+	return func(c *config) {
+		c.httpCache = cache
+	}
+}
+
+// WithConnectionReuse lets the GET reuse the TCP (and TLS) connection of the POST,
+// saving a handshake per request. Gradio servers keep connections alive, so it only
+// takes keep-alives on the transport and a fully drained POST response. A transport with
+// keep-alives disabled is replaced by a clone with them enabled, like WithKeepAlive does;
+// transports other than *http.Transport are left as they are.
+func WithConnectionReuse() Option {
+	return func(c *config) {
+		c.connReuse = true
+		if t, err := c.cloneTransport(); err == nil && t.DisableKeepAlives {
+			t.DisableKeepAlives = false
+			c.setTransport(t)
+		}
+	}
+}
+
+// WithKeepAlive turns HTTP keep-alives on or off. Enabled, idle connections are kept for
+// idleTimeout (0 for no limit) and up to 10 per host, so long-running programs making many
+// sequential requests skip a TCP and TLS handshake per request. Disabled, every request
+// opens a new connection. Like WithProxy it works on a clone of the transport.
+func WithKeepAlive(enable bool, idleTimeout time.Duration) Option {
+	return func(c *config) {
+		t, err := c.cloneTransport()
+		if err != nil {
+			if c.optErr == nil {
+				c.optErr = fmt.Errorf("hfs keep-alive: %w", err)
+			}
+			return
+		}
+		t.DisableKeepAlives = !enable
+		if enable {
+			t.IdleConnTimeout = idleTimeout
+			t.MaxIdleConnsPerHost = 10
+		}
+		c.setTransport(t)
+	}
+}
+
+// WithHTTP2 forces HTTP/2 on or off. Enabled, the transport is set up with
+// golang.org/x/net/http2 to negotiate HTTP/2 through ALPN, so the event streams of concurrent
+// requests share one connection instead of holding one each. Disabled, only HTTP/1.1 is used.
+// It sets the TLS config's ALPN protocols, so apply WithTLSConfig before it.
+func WithHTTP2(enable bool) Option {
+	return func(c *config) {
+		if err := c.setHTTP2(enable); err != nil && c.optErr == nil {
+			c.optErr = err
+		}
+	}
+}
+
+func (c *config) setHTTP2(enable bool) error {
+	t, err := c.cloneTransport()
+	if err != nil {
+		return fmt.Errorf("hfs http2: %w", err)
+	}
+	// A transport that already negotiated HTTP/2 has it registered, which ConfigureTransport refuses.
+	t.TLSNextProto = nil
+	if enable {
+		if err := http2.ConfigureTransport(t); err != nil {
+			return fmt.Errorf("hfs http2: %w", err)
+		}
+	} else {
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{} // non-nil and empty disables HTTP/2
+		if t.TLSClientConfig != nil {
+			t.TLSClientConfig = t.TLSClientConfig.Clone()
+			t.TLSClientConfig.NextProtos = slices.DeleteFunc(t.TLSClientConfig.NextProtos, func(p string) bool { return p == "h2" })
+		}
+	}
+	c.setTransport(t)
+	return nil
+}
+
+// WithGeneratingTimeout fails Do() with ErrGeneratingTimeout when no "generating" event arrives
+// for d after the previous one, i.e. the model stalled mid-generation with the stream left open.
+// Unlike WithTimeout it does not limit the total duration.
+func WithGeneratingTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.generatingTimeout = d
+	}
+}
+
+// WithRetry retries the full POST + GET round trip on temporary network errors and 429/503 replies,
+// up to maxAttempts attempts in total. Attempt n waits initialDelay * multiplier^(n-1) before retrying.
+func WithRetry(maxAttempts int, initialDelay time.Duration, multiplier float64) Option {
+	return func(c *config) {
+		c.retry.maxAttempts = maxAttempts
+		c.retry.initialDelay = initialDelay
+		c.retry.multiplier = multiplier
+	}
+}
+
+// WithRetryJitter randomizes each retry delay by up to ±fraction of it, e.g. 0.1 for ±10%.
+func WithRetryJitter(fraction float64) Option {
+	return func(c *config) {
+		c.retry.jitter = fraction
+	}
+}
+
+// WithRetryCallback calls fn before every retry with the failed attempt number and its error.
+func WithRetryCallback(fn func(attempt int, err error)) Option {
+	return func(c *config) {
+		c.retry.callback = fn
+	}
+}
+
+// WithUploader sets the Uploader of FileData created with h.NewFileData().
+func WithUploader(u Uploader) Option {
+	return func(c *config) {
+		c.uploader = u
+	}
+}
+
+// WithLogger logs every request phase ("post", "get", "parse") to l: at Debug level with
+// the url, status_code, duration_ms, event_id and request_id keys, or at Error level with an extra error key.
+// Defaults to slog.Default(), which drops the Debug records.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// WithTracer wraps every request phase in an "hfs.post", "hfs.get" or "hfs.parse" span
+// with http.url, http.status_code, hfs.event_id, hfs.endpoint and hfs.request_id attributes.
+// Only the OpenTelemetry API is used; plug in any SDK through t.
+func WithTracer(t trace.Tracer) Option {
+	return func(c *config) {
+		c.tracer = t
+	}
+}
+
+// WithMetrics reports every request, and every upload of FileData created with h.NewFileData(), to m.
+// See the metrics sub-package for a Prometheus implementation.
+func WithMetrics(m MetricsRecorder) Option {
+	return func(c *config) {
+		c.metrics = m
+	}
+}
+
+// WithRateLimit allows at most rps requests per second, waiting before each POST as needed.
+// The burst defaults to ceil(rps); see WithRateLimitBurst. The wait honours the request's context.
+// An rps of 0 or less removes the limit.
+func WithRateLimit(rps float64) Option {
+	return func(c *config) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(rps), c.rateLimitBurst(rps))
+	}
+}
+
+// WithRateLimitBurst sets how many requests WithRateLimit lets through at once after a quiet period.
+// A burst of 0 or less restores the default of ceil(rps).
+func WithRateLimitBurst(b int) Option {
+	return func(c *config) {
+		c.limiterBurst = b
+		if c.limiter != nil {
+			c.limiter.SetBurst(c.rateLimitBurst(float64(c.limiter.Limit())))
+		}
+	}
+}
+
+// rateLimitBurst returns the burst of a rate limit of rps requests per second.
+func (c *config) rateLimitBurst(rps float64) int {
+	if c.limiterBurst > 0 {
+		return c.limiterBurst
+	}
+	return int(math.Ceil(rps))
+}
+
+// WithCircuitBreaker fails requests with ErrCircuitOpen, without sending anything, after threshold
+// consecutive failures. Once resetAfter has passed since the last failure one request is let through:
+// if it succeeds requests flow again, otherwise the circuit stays open for another resetAfter.
+// A request counts once, however many retries WithRetry makes.
+func WithCircuitBreaker(threshold int, resetAfter time.Duration) Option {
+	return func(c *config) {
+		c.breaker = newCircuitBreaker(threshold, resetAfter)
+	}
+}
+
+// WithPropagateAuthToDownloads sends the space's Authorization header, e.g. from WithBearerToken,
+// along when downloading output files of the space. It is attached to FileData outputs and used by h.GetFileData().
+// Files on other hosts never get it, so the token does not leak to third parties.
+func WithPropagateAuthToDownloads() Option {
+	return func(c *config) {
+		c.propagateAuth = true
+	}
+}
+
+// authorizeDownload adds the Authorization header to fd if WithPropagateAuthToDownloads is set
+// and fd is served by the space's own host.
+func (c *config) authorizeDownload(fd *FileData) {
+	auth, ok := c.header("Authorization")
+	if !c.propagateAuth || !ok || fd == nil {
+		return
+	}
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return
+	}
+	file, err := url.Parse(fd.URL)
+	if err != nil || !strings.EqualFold(base.Host, file.Host) {
+		return
+	}
+	fd.WithAuthHeader("Authorization", auth)
+}
+
+// authorizeOutput is authorizeDownload() for a decoded output holding a FileData.
+func (c *config) authorizeOutput(v any) {
+	switch v := v.(type) {
+	case *FileData:
+		c.authorizeDownload(v)
+	case **FileData:
+		c.authorizeDownload(*v)
+	case *GradioValue:
+		if fd, ok := v.Value.(*FileData); ok {
+			c.authorizeDownload(fd)
+		}
+	}
+}
+
+// WithRequestID sends an X-Request-ID header, generated by gen before each POST, with the POST
+// and the GET of that event, so client and server logs can be correlated. A nil gen generates UUIDs.
+// The ID is part of the error of a failed request and of the log records (request_id key).
+func WithRequestID(gen func() string) Option {
+	return func(c *config) {
+		if gen == nil {
+			gen = uuid.NewString
+		}
+		c.requestID = gen
+	}
+}
+
+// WithResponseSizeLimit fails requests with ErrResponseTooLarge when the POST response or the
+// event stream of the GET exceeds maxBytes, instead of reading whatever a misbehaving space sends.
+// The limit covers the whole stream, "generating" and heartbeat events included.
+func WithResponseSizeLimit(maxBytes int64) Option {
+	return func(c *config) {
+		c.responseLimit = maxBytes
+	}
+}
+
+// WithFnIndex adds "fn_index": index to the body of every request, for spaces whose endpoints
+// are told apart by function index. A negative index, the default, leaves it out.
+// DoByIndex() sends its own index regardless.
+func WithFnIndex(index int) Option {
+	return func(c *config) {
+		c.fnIndex = index
+	}
+}
+
+// WithDeduplicationWindow makes identical requests sent within d share one upstream call.
+// A duplicate waits for the first call if it is still in flight and gets its result.
+func WithDeduplicationWindow(d time.Duration) Option {
+	return func(c *config) {
+		c.dedupWindow = d
+	}
+}
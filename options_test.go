@@ -0,0 +1,361 @@
+package hfs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Options(t *testing.T) {
+	common := []Option{WithBearerToken("first"), WithTimeout(5 * time.Second)}
+	hfs := NewHfs[any, any]("test", append(common, WithBearerToken("second"), WithTimeout(time.Minute))...)
+	if got := hfs.Headers["Authorization"]; got != "Bearer second" {
+		t.Fatalf("expected later option to win, got %q", got)
+	}
+	if hfs.client.Timeout != time.Minute {
+		t.Fatalf("expected later timeout to win, got %v", hfs.client.Timeout)
+	}
+
+	hfs.WithBearerToken("third")
+	if got := hfs.Headers["Authorization"]; got != "Bearer third" {
+		t.Fatalf("expected method after options to win, got %q", got)
+	}
+
+	other := NewHfs[any, any]("other", common...)
+	if other.Headers["Authorization"] != "Bearer first" || other.client == hfs.client {
+		t.Fatalf("expected shared options to configure each space independently")
+	}
+}
+
+func Test_OptionsDefaults(t *testing.T) {
+	hfs := NewHfs[any, any]("test")
+	if hfs.BaseURL != "https://test.hf.space/gradio_api/call" {
+		t.Fatalf("unexpected BaseURL %q", hfs.BaseURL)
+	}
+	if !reflect.DeepEqual(hfs.Headers, map[string]string{"Content-Type": "application/json"}) {
+		t.Fatalf("unexpected default headers %v", hfs.Headers)
+	}
+	if hfs.eventIDField != "event_id" || hfs.client == nil || hfs.client.Timeout != 0 {
+		t.Fatalf("unexpected defaults: event ID field %q, client %v", hfs.eventIDField, hfs.client)
+	}
+	if hfs.client == http.DefaultClient || hfs.client.Transport == http.DefaultTransport {
+		t.Fatalf("expected a client of its own")
+	}
+	if hfs.retry.maxAttempts != 0 || hfs.dedupWindow != 0 || hfs.uploader != nil || hfs.httpCache != nil {
+		t.Fatalf("expected optional features to be off by default")
+	}
+}
+
+func Test_NewHFSpaceFromURL(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	hfs, err := NewHFSpaceFromURL[any, string](srv.URL+"/gradio_api/call", WithBearerToken("tok"))
+	if err != nil {
+		t.Fatalf("NewHFSpaceFromURL returned error: %v", err)
+	}
+	if hfs.BaseURL != srv.URL+"/gradio_api/call" || hfs.Headers["Authorization"] != "Bearer tok" {
+		t.Fatalf("unexpected BaseURL %q or headers %v", hfs.BaseURL, hfs.Headers)
+	}
+	if _, err := hfs.Do("/predict"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	want := []string{"POST /gradio_api/call/predict", "GET /gradio_api/call/predict/evt"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected requests %v, got %v", want, paths)
+	}
+
+	for _, raw := range []string{"localhost:7860", "/gradio_api/call", "http://", "://bad"} {
+		if _, err := NewHFSpaceFromURL[any, any](raw); err == nil {
+			t.Fatalf("expected error for base URL %q", raw)
+		}
+	}
+
+	if NewHFSpaceByName[any, any]("name").WithBaseURL("http://localhost:7860/gradio_api/call").BaseURL != "http://localhost:7860/gradio_api/call" {
+		t.Fatalf("expected WithBaseURL to replace the name-based URL")
+	}
+}
+
+func Test_WithProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.Method+" "+r.URL.String())
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer proxy.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{ServerName: "kept"}}}
+	hfs, err := NewHfs[any, string]("space").WithBaseURL("http://space.invalid/gradio_api/call").WithHTTPClient(client).WithProxyE(proxy.URL)
+	if err != nil {
+		t.Fatalf("WithProxyE returned error: %v", err)
+	}
+	if _, err := hfs.Do("/predict"); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	want := []string{"POST http://space.invalid/gradio_api/call/predict", "GET http://space.invalid/gradio_api/call/predict/evt"}
+	if !reflect.DeepEqual(proxied, want) {
+		t.Fatalf("expected proxied requests %v, got %v", want, proxied)
+	}
+	transport := hfs.client.Transport.(*http.Transport)
+	if transport.TLSClientConfig.ServerName != "kept" {
+		t.Fatalf("expected TLS settings to be preserved")
+	}
+	if client.Transport.(*http.Transport).Proxy != nil {
+		t.Fatalf("expected the client passed to WithHTTPClient to be left untouched")
+	}
+
+	if _, err := NewHfs[any, any]("space").WithProxyE("://bad"); err == nil {
+		t.Fatalf("expected error for malformed proxy URL")
+	}
+	if _, err := NewHfs[any, any]("space", WithProxy("://bad")).Do("/predict"); err == nil || !strings.Contains(err.Error(), "hfs proxy url parse") {
+		t.Fatalf("expected requests to fail with the proxy error, got %v", err)
+	}
+	before := NewHfs[any, any]("space")
+	if after, _ := before.WithProxyE(""); after.client.Transport.(*http.Transport).Proxy == nil {
+		t.Fatalf("expected empty proxy URL to keep the environment proxy settings")
+	}
+}
+
+func Test_WithTLSConfig(t *testing.T) {
+	cert, err := tls.LoadX509KeyPair("testdata/tls_cert.pem", "testdata/tls_key.pem")
+	if err != nil {
+		t.Fatalf("tls.LoadX509KeyPair() returned error: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	if _, err := newTestHfs[any, string](srv).Do("/predict"); err == nil {
+		t.Fatalf("expected the self-signed certificate to be rejected by default")
+	}
+
+	pem, err := os.ReadFile("testdata/tls_cert.pem")
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(pem)
+	cfg := &tls.Config{RootCAs: roots}
+
+	hfs := newTestHfs[any, string](srv).WithTLSConfig(cfg).WithProxy("http://proxy.invalid:3128").WithTimeout(time.Minute)
+	transport := hfs.client.Transport.(*http.Transport)
+	if transport.TLSClientConfig.RootCAs != roots || transport.Proxy == nil || hfs.client.Timeout != time.Minute {
+		t.Fatalf("expected TLS config, proxy and timeout to compose")
+	}
+	if tc := http.DefaultTransport.(*http.Transport).TLSClientConfig; tc != nil && tc.RootCAs == roots {
+		t.Fatalf("expected http.DefaultTransport to be left untouched")
+	}
+
+	res, err := newTestHfs[any, string](srv).WithTLSConfig(cfg).Do("/predict")
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("expected [ok], got %v", res)
+	}
+}
+
+// countingTLSServer is a fake Gradio server over TLS that counts the connections it accepts.
+func countingTLSServer(tb testing.TB) (*httptest.Server, *atomic.Int32) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.StartTLS()
+	tb.Cleanup(srv.Close)
+	return srv, &conns
+}
+
+func Test_WithKeepAlive(t *testing.T) {
+	srv, conns := countingTLSServer(t)
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig
+
+	for _, tc := range []struct {
+		enable   bool
+		maxConns int32
+	}{
+		{true, 2},
+		{false, 200},
+	} {
+		conns.Store(0)
+		hfs := newTestHfs[any, string](srv).WithTLSConfig(tlsConfig).WithKeepAlive(tc.enable, 90*time.Second)
+		transport := hfs.client.Transport.(*http.Transport)
+		if transport.DisableKeepAlives == tc.enable {
+			t.Fatalf("WithKeepAlive(%v) left DisableKeepAlives %v", tc.enable, transport.DisableKeepAlives)
+		}
+		if tc.enable && (transport.IdleConnTimeout != 90*time.Second || transport.MaxIdleConnsPerHost != 10) {
+			t.Fatalf("unexpected idle settings %v, %d", transport.IdleConnTimeout, transport.MaxIdleConnsPerHost)
+		}
+		for range 100 {
+			if _, err := hfs.Do("/predict"); err != nil {
+				t.Fatalf("Do returned error: %v", err)
+			}
+		}
+		if got := conns.Load(); got > tc.maxConns || (!tc.enable && got < tc.maxConns) {
+			t.Fatalf("WithKeepAlive(%v): expected at most %d connections, got %d", tc.enable, tc.maxConns, got)
+		}
+	}
+}
+
+// BenchmarkKeepAlive compares 100 sequential requests over TLS with and without keep-alives.
+func BenchmarkKeepAlive(b *testing.B) {
+	srv, _ := countingTLSServer(b)
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	for _, enable := range []bool{true, false} {
+		b.Run(fmt.Sprintf("enable=%v", enable), func(b *testing.B) {
+			hfs := newTestHfs[any, string](srv).WithTLSConfig(tlsConfig).WithKeepAlive(enable, 90*time.Second)
+			for b.Loop() {
+				for range 100 {
+					if _, err := hfs.Do("/predict"); err != nil {
+						b.Fatalf("Do returned error: %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_WithHTTP2(t *testing.T) {
+	protos := make(chan string, 4)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			protos <- r.Proto
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	tlsConfig.NextProtos = nil
+
+	for _, tc := range []struct {
+		enable bool
+		proto  string
+	}{
+		{true, "HTTP/2.0"},
+		{false, "HTTP/1.1"},
+	} {
+		hfs := newTestHfs[any, string](srv).WithTLSConfig(tlsConfig).WithHTTP2(tc.enable)
+		if _, err := hfs.Do("/predict"); err != nil {
+			t.Fatalf("WithHTTP2(%v): Do returned error: %v", tc.enable, err)
+		}
+		if got := <-protos; got != tc.proto {
+			t.Fatalf("WithHTTP2(%v): expected %s, got %s", tc.enable, tc.proto, got)
+		}
+		// Applying it again on a transport that already negotiated must work too.
+		if _, err := hfs.WithHTTP2(tc.enable).Do("/predict"); err != nil {
+			t.Fatalf("WithHTTP2(%v) twice: Do returned error: %v", tc.enable, err)
+		}
+		if got := <-protos; got != tc.proto {
+			t.Fatalf("WithHTTP2(%v) twice: expected %s, got %s", tc.enable, tc.proto, got)
+		}
+	}
+}
+
+func Test_WithHeaderFunc(t *testing.T) {
+	var auths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			auths = append(auths, r.Header.Get("Authorization"))
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	var n atomic.Int32
+	hfs := newTestHfs[any, string](srv).
+		WithBearerToken("static").
+		WithHeaderFunc("Authorization", func() string { return fmt.Sprintf("Bearer token-%d", n.Add(1)) })
+	for range 2 {
+		if _, err := hfs.Do("/predict", "x"); err != nil {
+			t.Fatalf("Do returned error: %v", err)
+		}
+	}
+	if len(auths) != 2 || auths[0] == auths[1] || !strings.HasPrefix(auths[0], "Bearer token-") {
+		t.Fatalf("expected the header to be refreshed per request, got %v", auths)
+	}
+	if hfs.Headers["Authorization"] != "Bearer static" {
+		t.Fatalf("expected the static header to be kept, got %q", hfs.Headers["Authorization"])
+	}
+}
+
+func Test_WithTransportMiddleware(t *testing.T) {
+	srv := fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", nil)
+	defer srv.Close()
+
+	var count atomic.Int32
+	var order []string
+	middleware := func(name string, counter *atomic.Int32) func(http.RoundTripper) http.RoundTripper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				if counter != nil {
+					counter.Add(1)
+				}
+				order = append(order, name)
+				return next.RoundTrip(r)
+			})
+		}
+	}
+	hfs := newTestHfs[any, string](srv).
+		WithTimeout(42 * time.Second).
+		WithTransportMiddleware(middleware("outer", &count)).
+		WithTransportMiddleware(middleware("inner", nil))
+
+	for range 2 {
+		if _, err := hfs.Do("/predict", "x"); err != nil {
+			t.Fatalf("Do returned error: %v", err)
+		}
+	}
+	// Each Do is a POST and a GET.
+	if n := count.Load(); n != 4 {
+		t.Fatalf("expected 4 round trips, got %d", n)
+	}
+	if len(order) != 8 || order[0] != "outer" || order[1] != "inner" {
+		t.Fatalf("expected the first middleware to be outermost, got %v", order)
+	}
+	if hfs.client.Timeout != 42*time.Second {
+		t.Fatalf("expected the timeout to be kept, got %v", hfs.client.Timeout)
+	}
+}
@@ -0,0 +1,89 @@
+package hfs
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// UnserializableParamError reports a param that cannot be encoded as JSON.
+type UnserializableParamError struct {
+	Index int    // position in params
+	Path  string // location inside the param, empty if the param itself is the problem
+	Type  string // Go type of the offending value
+}
+
+func (e *UnserializableParamError) Error() string {
+	return fmt.Sprintf("hfs param %d%s: unserializable type %s", e.Index, e.Path, e.Type)
+}
+
+func (e *UnserializableParamError) Is(target error) bool {
+	return target == ErrUnserializableParam
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// maxParamDepth bounds the walk so self-referencing values cannot loop forever.
+const maxParamDepth = 32
+
+// validateParams finds chan, func and complex values that json.Marshal would reject,
+// so the error can name the param instead of a generic "json: unsupported type".
+func validateParams[I any](params []I) error {
+	for i, p := range params {
+		if path, typ, ok := findUnserializable(reflect.ValueOf(p), "", 0); !ok {
+			return &UnserializableParamError{Index: i, Path: path, Type: typ}
+		}
+	}
+	return nil
+}
+
+func findUnserializable(v reflect.Value, path string, depth int) (string, string, bool) {
+	if !v.IsValid() || depth > maxParamDepth {
+		return "", "", true
+	}
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return "", "", true
+	}
+
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return path, t.String(), false
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return "", "", true
+		}
+		return findUnserializable(v.Elem(), path, depth+1)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() || f.Tag.Get("json") == "-" {
+				continue
+			}
+			if p, typ, ok := findUnserializable(v.Field(i), path+"."+f.Name, depth+1); !ok {
+				return p, typ, false
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if p, typ, ok := findUnserializable(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), depth+1); !ok {
+				return p, typ, false
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "", "", true
+		}
+		for i := range v.Len() {
+			if p, typ, ok := findUnserializable(v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1); !ok {
+				return p, typ, false
+			}
+		}
+	}
+	return "", "", true
+}
@@ -0,0 +1,52 @@
+package hfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_ValidateParams(t *testing.T) {
+	type withFunc struct {
+		Name string
+		Fn   func()
+	}
+	type ignored struct {
+		Ch chan int `json:"-"`
+		ch chan int
+	}
+
+	cases := []struct {
+		params []any
+		index  int
+		path   string
+		typ    string
+	}{
+		{[]any{"ok", make(chan int)}, 1, "", "chan int"},
+		{[]any{withFunc{Name: "x"}}, 0, ".Fn", "func()"},
+		{[]any{1, map[string]any{"z": complex(1, 2)}}, 1, "[z]", "complex128"},
+		{[]any{[]any{1, []any{func() {}}}}, 0, "[1][0]", "func()"},
+	}
+	for _, c := range cases {
+		err := validateParams(c.params)
+		var perr *UnserializableParamError
+		if !errors.Is(err, ErrUnserializableParam) || !errors.As(err, &perr) {
+			t.Fatalf("expected ErrUnserializableParam for %v, got %v", c.params, err)
+		}
+		if perr.Index != c.index || perr.Path != c.path || perr.Type != c.typ {
+			t.Fatalf("expected param %d%s %s, got %+v", c.index, c.path, c.typ, perr)
+		}
+	}
+
+	fd := NewFileData("a.png")
+	if err := validateParams([]any{"text", 1.5, fd, ignored{}, []byte("raw"), nil}); err != nil {
+		t.Fatalf("validateParams() rejected serializable params: %v", err)
+	}
+}
+
+func Test_DoRejectsUnserializableParam(t *testing.T) {
+	srv := fakeGradio(t, "event: complete\ndata: [1]\n\n", nil)
+	_, err := newTestHfs[any, any](srv).Do(test_endpoint, "ok", make(chan int))
+	if !errors.Is(err, ErrUnserializableParam) {
+		t.Fatalf("expected ErrUnserializableParam, got %v", err)
+	}
+}
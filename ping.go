@@ -0,0 +1,132 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Ping checks whether the space is awake with a GET to its root URL, following redirects.
+// A sleeping or still starting space replies 503, which makes Ping return an error matching ErrSpaceSleeping.
+// Visiting the root is also what wakes a sleeping space up.
+func (h *HFSpace[I, O]) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", h.rootURL(), nil)
+	if err != nil {
+		return fmt.Errorf("hfs ping req create: %w", err)
+	}
+	h.applyHeaders(req.Header)
+
+	resp, err := h.send(req)
+	if err != nil {
+		return fmt.Errorf("hfs ping req exec: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if err := checkStatus(resp); err != nil {
+		err = fmt.Errorf("hfs ping resp: %w", err)
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			return withKind(ErrSpaceSleeping, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// WakeAndWait pings the space every pollInterval until it is awake or ctx is done.
+// Spaces usually take 30 to 60 seconds to wake up, so give ctx a deadline well above that.
+// On expiry the error wraps both ctx.Err() and the last Ping error.
+func (h *HFSpace[I, O]) WakeAndWait(ctx context.Context, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	var last error // last Ping error not caused by ctx itself
+	for {
+		err := h.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() == nil {
+			last = err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if last == nil {
+				return fmt.Errorf("hfs wake: %w", ctx.Err())
+			}
+			return fmt.Errorf("hfs wake: %w: %w", ctx.Err(), last)
+		}
+	}
+}
+
+// Warmup wakes the space with a dummy call of endpoint with no inputs, {"data": []}, and discards the result.
+// Only network failures and 5xx replies are returned: the call itself usually fails for lack of inputs,
+// with an error event or a 4xx, but it has done its job once the space answers.
+func (h *HFSpace[I, O]) Warmup(ctx context.Context, endpoint string) error {
+	return h.warmup(ctx, h.endpointURL(endpoint))
+}
+
+func (h *HFSpace[I, O]) warmup(ctx context.Context, fullURL string) error {
+	body, err := marshalPayload(h.dataPayload([]any{}))
+	if err != nil {
+		return err
+	}
+	_, err = h.roundTrip(ctx, fullURL, body)
+	var herr *HTTPStatusError
+	switch {
+	case err == nil, errors.Is(err, ErrNoData), errors.Is(err, ErrEventError), errors.Is(err, ErrDecodeFailure):
+		return nil
+	case errors.As(err, &herr) && herr.StatusCode < 500:
+		return nil
+	}
+	return fmt.Errorf("hfs warmup: %w", err)
+}
+
+// latencySamples is the number of timed requests of EstimateLatency.
+const latencySamples = 5
+
+// EstimateLatency measures the typical round trip of endpoint: after warmupRuns Warmup() calls,
+// whose timings are discarded, it times 5 more and returns the median. Like Warmup(), the calls
+// have no inputs, and replies without output or with an error event still count as answers.
+func (h *HFSpace[I, O]) EstimateLatency(ctx context.Context, endpoint string, warmupRuns int) (time.Duration, error) {
+	fullURL := h.endpointURL(endpoint)
+	for range warmupRuns {
+		if err := h.warmup(ctx, fullURL); err != nil {
+			return 0, err
+		}
+	}
+	samples := make([]time.Duration, latencySamples)
+	for i := range samples {
+		start := time.Now()
+		if err := h.warmup(ctx, fullURL); err != nil {
+			return 0, err
+		}
+		samples[i] = time.Since(start)
+	}
+	slices.Sort(samples)
+	return samples[latencySamples/2], nil
+}
+
+// WithAutoWarmup makes the first request of the space call Warmup() and try again
+// if its POST is answered with a 503, as a sleeping or starting space does.
+func WithAutoWarmup() Option {
+	return func(c *config) {
+		c.autoWarmup = true
+	}
+}
+
+// isSleeping reports whether err is a 503 reply.
+func isSleeping(err error) bool {
+	var herr *HTTPStatusError
+	return errors.As(err, &herr) && herr.StatusCode == http.StatusServiceUnavailable
+}
+
+// rootURL turns a BaseURL like "https://name.hf.space/gradio_api/call" into "https://name.hf.space/".
+func (h *HFSpace[I, O]) rootURL() string {
+	return strings.TrimSuffix(h.BaseURL, h.endpointBase) + "/"
+}
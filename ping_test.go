@@ -0,0 +1,130 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Ping(t *testing.T) {
+	var pings atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			if pings.Add(1) <= 2 {
+				http.Error(w, "Space is starting", http.StatusServiceUnavailable)
+				return
+			}
+			http.Redirect(w, r, "/app", http.StatusFound)
+		case "/app":
+			w.Write([]byte("<html>gradio</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	hfs := newTestHfs[any, any](srv)
+
+	if err := hfs.Ping(context.Background()); !errors.Is(err, ErrSpaceSleeping) {
+		t.Fatalf("expected ErrSpaceSleeping, got %v", err)
+	}
+	if err := hfs.WakeAndWait(context.Background(), 10*time.Millisecond); err != nil {
+		t.Fatalf("WakeAndWait() returned error: %v", err)
+	}
+	if pings.Load() != 3 {
+		t.Fatalf("expected 3 pings, got %d", pings.Load())
+	}
+	if err := hfs.Ping(context.Background()); err != nil {
+		t.Fatalf("expected awake space to answer Ping, got %v", err)
+	}
+}
+
+func Test_WakeAndWaitTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Space is sleeping", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := newTestHfs[any, any](srv).WakeAndWait(ctx, 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrSpaceSleeping) {
+		t.Fatalf("expected deadline and sleeping errors, got %v", err)
+	}
+}
+
+func Test_Warmup(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if posts.Add(1) == 1 {
+				http.Error(w, "Space is starting", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: [\"ok\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	hfs := newTestHfs[any, string](srv).WithAutoWarmup()
+	res, err := hfs.Do("/predict", "x")
+	if err != nil || len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("Do() returned %v, %v", res, err)
+	}
+	if n := posts.Load(); n != 3 {
+		t.Fatalf("expected the 503, a warmup and the retried request, got %d POSTs", n)
+	}
+
+	posts.Store(0)
+	if _, err := hfs.Do("/predict", "x"); !errors.Is(err, ErrHTTPStatus) {
+		t.Fatalf("expected only the first request to warm up, got %v", err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gradio_api/call/bad":
+			http.Error(w, "missing inputs", http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, "down", http.StatusBadGateway)
+		}
+	}))
+	defer failing.Close()
+	if err := newTestHfs[any, string](failing).Warmup(context.Background(), "/bad"); err != nil {
+		t.Fatalf("expected a 4xx to count as awake, got %v", err)
+	}
+	if err := newTestHfs[any, string](failing).Warmup(context.Background(), "/predict"); !errors.Is(err, ErrHTTPStatus) {
+		t.Fatalf("expected a 5xx to be returned, got %v", err)
+	}
+}
+
+func Test_EstimateLatency(t *testing.T) {
+	const delay = 80 * time.Millisecond
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts.Add(1)
+			time.Sleep(delay)
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: complete\ndata: null\n\n")) // no output for empty inputs
+	}))
+	defer srv.Close()
+
+	got, err := newTestHfs[any, string](srv).EstimateLatency(context.Background(), "/predict", 2)
+	if err != nil {
+		t.Fatalf("EstimateLatency() returned error: %v", err)
+	}
+	if got < delay || got > delay+delay/10 {
+		t.Fatalf("expected about %v, got %v", delay, got)
+	}
+	if n := posts.Load(); n != 7 {
+		t.Fatalf("expected 2 warmup and 5 timed requests, got %d", n)
+	}
+}
@@ -0,0 +1,43 @@
+package hfs
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pipeline chains two spaces: the first output of First is the only input of Second,
+// e.g. speech-to-text followed by text-to-image.
+type Pipeline[A, B, C any] struct {
+	First  *HFSpace[A, B]
+	Second *HFSpace[B, C]
+}
+
+// PipelineError is returned by Pipeline.Run. Stage is 1 if First failed, 2 if Second did.
+type PipelineError struct {
+	Stage int
+	Err   error
+}
+
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("hfs pipeline stage %d: %v", e.Stage, e.Err)
+}
+
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+// Run calls firstEndpoint of First with params, then secondEndpoint of Second with the first output.
+func (p *Pipeline[A, B, C]) Run(ctx context.Context, firstEndpoint, secondEndpoint string, params ...A) ([]C, error) {
+	mid, err := p.First.DoWithContext(ctx, firstEndpoint, params...)
+	if err != nil {
+		return nil, &PipelineError{Stage: 1, Err: err}
+	}
+	if len(mid) == 0 {
+		return nil, &PipelineError{Stage: 1, Err: ErrNoData}
+	}
+	res, err := p.Second.DoWithContext(ctx, secondEndpoint, mid[0])
+	if err != nil {
+		return nil, &PipelineError{Stage: 2, Err: err}
+	}
+	return res, nil
+}
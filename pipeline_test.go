@@ -0,0 +1,58 @@
+package hfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// echoGradio serves a space whose endpoint returns its inputs, each prefixed with prefix.
+func echoGradio(t *testing.T, prefix string) *httptest.Server {
+	t.Helper()
+	var last []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body struct{ Data []string }
+			json.NewDecoder(r.Body).Decode(&body)
+			last = body.Data
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		out := []string{}
+		for _, s := range last {
+			out = append(out, prefix+s)
+		}
+		data, _ := json.Marshal(out)
+		w.Write([]byte("event: complete\ndata: " + string(data) + "\n\n"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func Test_Pipeline(t *testing.T) {
+	p := &Pipeline[string, string, string]{
+		First:  newTestHfs[string, string](echoGradio(t, "text:")),
+		Second: newTestHfs[string, string](echoGradio(t, "image:")),
+	}
+	res, err := p.Run(context.Background(), "/transcribe", "/draw", "audio", "ignored")
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(res) != 1 || res[0] != "image:text:audio" {
+		t.Fatalf("expected the first output to feed the second stage, got %v", res)
+	}
+
+	p.Second = newTestHfs[string, string](fakeGradio(t, "event: error\ndata: null\n\n", nil))
+	var perr *PipelineError
+	if _, err := p.Run(context.Background(), "/transcribe", "/draw", "audio"); !errors.As(err, &perr) || perr.Stage != 2 || !errors.Is(err, ErrEventError) {
+		t.Fatalf("expected a stage 2 event error, got %v", err)
+	}
+
+	p.First = newTestHfs[string, string](fakeGradio(t, "event: complete\ndata: []\n\n", nil))
+	if _, err := p.Run(context.Background(), "/transcribe", "/draw", "audio"); !errors.As(err, &perr) || perr.Stage != 1 || !errors.Is(err, ErrNoData) {
+		t.Fatalf("expected a stage 1 ErrNoData, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	mrand "math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// LoadBalanceStrategy chooses the space of an HFSpacePool that gets a request.
+type LoadBalanceStrategy int
+
+const (
+	RoundRobin   LoadBalanceStrategy = iota // each space in turn
+	LeastPending                            // the space with the fewest requests in flight
+	Random                                  // a random space
+)
+
+// HFSpacePool spreads requests over several copies of a space, e.g. duplicates of a space
+// running on their own free GPU quota. A request failing on one space is tried on the others;
+// if all of them fail, the error of the last one is returned.
+// A pool can also be built as a struct literal.
+type HFSpacePool[I, O any] struct {
+	// Spaces can be configured one by one, e.g. with their own token, and added or removed
+	// while the pool has no requests running.
+	Spaces   []*HFSpace[I, O]
+	Strategy LoadBalanceStrategy
+
+	next    atomic.Uint64
+	mu      sync.Mutex
+	pending map[*HFSpace[I, O]]int64 // in-flight requests per space, guarded by mu
+}
+
+var _ Doer[any, any] = (*HFSpacePool[any, any])(nil)
+
+// NewHFSpacePool creates a pool of one space per name, each configured with opts.
+func NewHFSpacePool[I, O any](names []string, strategy LoadBalanceStrategy, opts ...Option) *HFSpacePool[I, O] {
+	p := &HFSpacePool[I, O]{Strategy: strategy}
+	for _, name := range names {
+		p.Spaces = append(p.Spaces, NewHfs[I, O](name, opts...))
+	}
+	return p
+}
+
+// Do performs the request on a space chosen by Strategy.
+func (p *HFSpacePool[I, O]) Do(endpoint string, params ...I) ([]O, error) {
+	return p.DoWithContext(context.Background(), endpoint, params...)
+}
+
+// DoWithContext is Do() with a context. Once ctx is done, no further space is tried.
+func (p *HFSpacePool[I, O]) DoWithContext(ctx context.Context, endpoint string, params ...I) ([]O, error) {
+	spaces := p.Spaces
+	if len(spaces) == 0 {
+		return nil, errors.New("hfs pool has no spaces")
+	}
+	first := p.pick(spaces)
+	var err error
+	for i := range spaces {
+		space := spaces[(first+i)%len(spaces)]
+		var res []O
+		p.addPending(space, 1)
+		res, err = space.DoWithContext(ctx, endpoint, params...)
+		p.addPending(space, -1)
+		if err == nil || ctx.Err() != nil {
+			return res, err
+		}
+	}
+	return nil, err
+}
+
+// Pending returns the number of requests in flight on each space.
+func (p *HFSpacePool[I, O]) Pending() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := make([]int64, len(p.Spaces))
+	for i, space := range p.Spaces {
+		n[i] = p.pending[space]
+	}
+	return n
+}
+
+func (p *HFSpacePool[I, O]) addPending(space *HFSpace[I, O], delta int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending == nil {
+		p.pending = map[*HFSpace[I, O]]int64{}
+	}
+	if p.pending[space] += delta; p.pending[space] == 0 {
+		delete(p.pending, space)
+	}
+}
+
+// pick returns the index of the space of spaces to try first.
+func (p *HFSpacePool[I, O]) pick(spaces []*HFSpace[I, O]) int {
+	switch p.Strategy {
+	case LeastPending:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		best := 0
+		for i, space := range spaces {
+			if p.pending[space] < p.pending[spaces[best]] {
+				best = i
+			}
+		}
+		return best
+	case Random:
+		return mrand.Intn(len(spaces))
+	default:
+		return int((p.next.Add(1) - 1) % uint64(len(spaces)))
+	}
+}
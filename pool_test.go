@@ -0,0 +1,118 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// poolServer answers with its own name, after release is closed if not nil.
+func poolServer(t *testing.T, name string, status int, release chan struct{}) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			http.Error(w, "down", status)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		if release != nil {
+			<-release
+		}
+		w.Write([]byte("event: complete\ndata: [\"" + name + "\"]\n\n"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestPool(strategy LoadBalanceStrategy, srvs ...*httptest.Server) *HFSpacePool[any, string] {
+	p := NewHFSpacePool[any, string](make([]string, len(srvs)), strategy)
+	for i, srv := range srvs {
+		p.Spaces[i].BaseURL = srv.URL + "/gradio_api/call"
+	}
+	return p
+}
+
+func Test_HFSpacePoolRoundRobin(t *testing.T) {
+	p := newTestPool(RoundRobin, poolServer(t, "a", 200, nil), poolServer(t, "b", 200, nil), poolServer(t, "c", 200, nil))
+	var got []string
+	for range 4 {
+		res, err := p.Do("/predict", "x")
+		if err != nil {
+			t.Fatalf("Do() returned error: %v", err)
+		}
+		got = append(got, res[0])
+	}
+	if want := []string{"a", "b", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func Test_HFSpacePoolLeastPending(t *testing.T) {
+	release := make(chan struct{})
+	p := newTestPool(LeastPending, poolServer(t, "a", 200, release), poolServer(t, "b", 200, nil))
+
+	done := make(chan error)
+	go func() {
+		_, err := p.Do("/predict", "slow")
+		done <- err
+	}()
+	for p.Pending()[0] == 0 { // wait for the slow request to be in flight on a
+		time.Sleep(time.Millisecond)
+	}
+	res, err := p.Do("/predict", "x")
+	if err != nil || res[0] != "b" {
+		t.Fatalf("expected the idle space b, got %v, %v", res, err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(p.Pending(), []int64{0, 0}) {
+		t.Fatalf("expected no requests in flight, got %v", p.Pending())
+	}
+}
+
+func Test_HFSpacePoolFailover(t *testing.T) {
+	p := newTestPool(RoundRobin, poolServer(t, "a", http.StatusServiceUnavailable, nil), poolServer(t, "b", 200, nil))
+	if res, err := p.Do("/predict", "x"); err != nil || res[0] != "b" {
+		t.Fatalf("expected failover to b, got %v, %v", res, err)
+	}
+
+	p = newTestPool(Random, poolServer(t, "a", http.StatusBadGateway, nil), poolServer(t, "b", http.StatusBadGateway, nil))
+	if _, err := p.DoWithContext(context.Background(), "/predict", "x"); !errors.Is(err, ErrHTTPStatus) {
+		t.Fatalf("expected the last error when all spaces fail, got %v", err)
+	}
+}
+
+func Test_HFSpacePoolLiteral(t *testing.T) {
+	a := newTestHfs[any, string](poolServer(t, "a", 200, nil))
+	p := &HFSpacePool[any, string]{Spaces: []*HFSpace[any, string]{a}, Strategy: LeastPending}
+	if res, err := p.Do("/predict", "x"); err != nil || res[0] != "a" {
+		t.Fatalf("Do() returned %v, %v", res, err)
+	}
+
+	p.Spaces = append(p.Spaces, newTestHfs[any, string](poolServer(t, "b", 200, nil)))
+	p.Strategy = RoundRobin
+	var got []string
+	for range 2 {
+		res, err := p.Do("/predict", "x")
+		if err != nil {
+			t.Fatalf("Do() returned error: %v", err)
+		}
+		got = append(got, res[0])
+	}
+	if !slices.Contains(got, "b") {
+		t.Fatalf("expected the appended space to get requests, got %v", got)
+	}
+	if pending := p.Pending(); !reflect.DeepEqual(pending, []int64{0, 0}) {
+		t.Fatalf("expected no requests pending, got %v", pending)
+	}
+}
@@ -2,6 +2,7 @@ package hfs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,17 +11,58 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 const (
 	ENDPOINT = "https://qu.ax/upload.php"
+
+	// MaxUploadSize is the largest file Quax accepts. Quax has no chunked or resumable uploads,
+	// so a file is always sent as one multipart request and larger files cannot be split up.
+	MaxUploadSize = 209715200
 )
 
 type Quax struct {
 	Client   *http.Client
 	Userhash string
+
+	allowedHosts []string
+	endpoint     string
+	progress     ProgressFunc
+}
+
+// ProgressFunc receives the number of bytes uploaded so far and the total, or -1 if unknown.
+type ProgressFunc func(uploaded, total int64)
+
+// progressReader reports the running byte count after every read.
+type progressReader struct {
+	r     io.Reader
+	n     int64
+	total int64
+	fn    ProgressFunc
+}
+
+// newProgressReader wraps r to report progress to fn, or returns r as is if fn is nil.
+func newProgressReader(r io.Reader, total int64, fn ProgressFunc) io.Reader {
+	if fn == nil {
+		return r
+	}
+	return &progressReader{r: r, total: total, fn: fn}
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.n += int64(n)
+		pr.fn(pr.n, pr.total)
+	}
+	return n, err
 }
 
 type File struct {
@@ -32,18 +74,96 @@ type QuaxResponse struct {
 	Files   []File `json:"files"`
 }
 
-func NewQuax(client *http.Client) *Quax {
-	if client == nil {
-		client = &http.Client{
+// NewQuax creates a Quax uploader with a default HTTP client (30s timeout), configured by opts.
+func NewQuax(opts ...QuaxOption) *Quax {
+	quax := &Quax{
+		Client: &http.Client{
 			Timeout: 30 * time.Second,
+		},
+		allowedHosts: []string{"qu.ax"},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(quax)
 		}
 	}
+	return quax
+}
+
+// QuaxOption configures a Quax, see NewQuax().
+type QuaxOption func(*Quax)
 
-	return &Quax{
-		Client: client,
+// QuaxWithClient sets the http.Client used for uploads. A nil client keeps the default one.
+func QuaxWithClient(c *http.Client) QuaxOption {
+	return func(quax *Quax) {
+		if c != nil {
+			quax.Client = c
+		}
 	}
 }
 
+// QuaxWithUserhash uploads the files to the Quax account identified by h.
+func QuaxWithUserhash(h string) QuaxOption {
+	return func(quax *Quax) { quax.Userhash = h }
+}
+
+// QuaxWithTimeout sets the timeout of the uploads, like Quax.WithTimeout().
+func QuaxWithTimeout(d time.Duration) QuaxOption {
+	return func(quax *Quax) { quax.WithTimeout(d) }
+}
+
+// WithHTTPClient allows setting a custom http.Client.
+func (quax *Quax) WithHTTPClient(client *http.Client) *Quax {
+	quax.Client = client
+	return quax
+}
+
+// WithTimeout sets a custom timeout on the underlying HTTP client. The timeout is set on a copy
+// of the client, so a client shared through WithHTTPClient, or http.DefaultClient, is not modified.
+func (quax *Quax) WithTimeout(d time.Duration) *Quax {
+	client := *quax.Client
+	client.Timeout = d
+	quax.Client = &client
+	return quax
+}
+
+// WithUserhash uploads the files to the Quax account identified by hash.
+func (quax *Quax) WithUserhash(hash string) *Quax {
+	quax.Userhash = hash
+	return quax
+}
+
+// WithAllowedURLHosts sets which hosts an upload URL returned by Quax may point to.
+// Defaults to ["qu.ax"].
+func (quax *Quax) WithAllowedURLHosts(hosts []string) *Quax {
+	quax.allowedHosts = hosts
+	return quax
+}
+
+// WithProgressFunc reports upload progress to f as the file is sent.
+func (quax *Quax) WithProgressFunc(f ProgressFunc) *Quax {
+	quax.progress = f
+	return quax
+}
+
+// ValidateUploadURL checks that rawURL points to one of the allowed hosts.
+func (quax *Quax) ValidateUploadURL(rawURL string) error {
+	uri, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("quax upload url parse: %w", err)
+	}
+	hosts := quax.allowedHosts
+	if hosts == nil {
+		hosts = []string{"qu.ax"}
+	}
+	for _, host := range hosts {
+		if strings.EqualFold(uri.Hostname(), host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("quax upload url host %q not allowed", uri.Hostname())
+}
+
 // Upload file or URI to the Quax. It returns an URL string and error.
 func (quax *Quax) Upload(v ...any) (string, error) {
 	if len(v) == 0 {
@@ -75,48 +195,209 @@ func (quax *Quax) Upload(v ...any) (string, error) {
 	return "", fmt.Errorf(`unhandled`)
 }
 
-func (quax *Quax) rawUpload(b []byte, name string) (string, error) {
-	r, w := io.Pipe()
-	m := multipart.NewWriter(w)
+// UploadURL re-uploads the file at remoteURL to Quax. Quax cannot fetch URLs itself,
+// so the download is streamed straight into the upload without buffering it.
+func (quax *Quax) UploadURL(ctx context.Context, remoteURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, remoteURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("quax remote req create: %w", err)
+	}
+	resp, err := quax.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("quax remote req exec: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return "", fmt.Errorf("quax remote resp: %w", err)
+	}
+	if err := checkUploadSize(resp.ContentLength); err != nil {
+		return "", err
+	}
 
-	go func() {
-		defer w.Close()
-		defer m.Close()
+	name := path.Base(req.URL.Path)
+	if name == "/" || name == "." {
+		name = "file"
+	}
+	if resp.ContentLength >= 0 {
+		return quax.sizedUpload(ctx, resp.Body, resp.ContentLength, name)
+	}
+	return quax.readerUpload(ctx, resp.Body, name)
+}
 
-		m.WriteField("reqtype", "fileupload")
-		m.WriteField("userhash", quax.Userhash)
-		part, err := m.CreateFormFile("files[]", filepath.Base(name))
-		if err != nil {
-			return
+// BatchFile is one file of a BatchUpload.
+type BatchFile struct {
+	Data []byte
+	Name string
+}
+
+// BatchUpload uploads files in a single multipart request, saving the per-request
+// overhead of Upload. The returned URLs are in the order of files.
+// If some files fail, the URLs of the others are still returned, with "" for the failed ones,
+// along with a *BatchUploadError listing the failed indices.
+func (quax *Quax) BatchUpload(ctx context.Context, files []BatchFile) ([]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	var body bytes.Buffer
+	m := multipart.NewWriter(&body)
+	m.WriteField("reqtype", "fileupload")
+	m.WriteField("userhash", quax.Userhash)
+	for i, f := range files {
+		if err := checkUploadSize(int64(len(f.Data))); err != nil {
+			return nil, fmt.Errorf("file %d: %w", i, err)
 		}
-		if _, err = io.Copy(part, bytes.NewBuffer(b)); err != nil {
-			return
+		part, err := m.CreateFormFile("files[]", filepath.Base(f.Name))
+		if err != nil {
+			return nil, err
 		}
-	}()
-	req, _ := http.NewRequest(http.MethodPost, ENDPOINT, r)
-	req.Header.Add("Content-Type", m.FormDataContentType())
+		part.Write(f.Data)
+	}
+	if err := m.Close(); err != nil {
+		return nil, err
+	}
 
-	resp, err := quax.Client.Do(req)
+	size := int64(body.Len())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, quax.uploadEndpoint(),
+		newProgressReader(&body, size, quax.progress))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
+	req.ContentLength = size
+	req.Header.Add("Content-Type", m.FormDataContentType())
 
-	body, err := io.ReadAll(resp.Body)
+	qr, err := quax.post(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var qr QuaxResponse
-	err = json.Unmarshal([]byte(body), &qr)
-	if err != nil {
-		return "", fmt.Errorf("quax upload response unmarshal: %w", err)
+	urls := make([]string, len(files))
+	var failed []int
+	var errs []error
+	for i := range files {
+		if !qr.Success || i >= len(qr.Files) || qr.Files[i].URL == "" {
+			failed = append(failed, i)
+			continue
+		}
+		if err := quax.ValidateUploadURL(qr.Files[i].URL); err != nil {
+			failed = append(failed, i)
+			errs = append(errs, err)
+			continue
+		}
+		urls[i] = qr.Files[i].URL
 	}
-	if !qr.Success || len(qr.Files) == 0 {
-		return "", fmt.Errorf("quax upload failed")
+	if len(failed) > 0 {
+		return urls, &BatchUploadError{Failed: failed, Err: errors.Join(errs...)}
 	}
+	return urls, nil
+}
 
-	return qr.Files[0].URL, nil
+// Delete is a stub: Quax offers no API to delete uploaded files.
+// It always returns an error matching ErrNotSupported.
+func (quax *Quax) Delete(ctx context.Context, fileURL string) error {
+	return fmt.Errorf("quax delete %s: quax has no deletion api: %w", fileURL, ErrNotSupported)
+}
+
+// QuaxUploader is an Uploader for Quax that retries failed uploads. Uploads are
+// independent of the calls to a space, so they have a retry policy of their own.
+type QuaxUploader struct {
+	Client     *http.Client
+	MaxRetries int           // retries after the first attempt
+	RetryDelay time.Duration // wait between attempts
+	Userhash   string
+
+	allowedHosts []string
+	endpoint     string
+}
+
+// NewQuaxUploader creates a QuaxUploader retrying 3 times, 1s apart,
+// with the client and userhash of NewQuax() unless set by opts.
+func NewQuaxUploader(opts ...QuaxOption) *QuaxUploader {
+	quax := NewQuax(opts...)
+	return &QuaxUploader{
+		Client:       quax.Client,
+		MaxRetries:   3,
+		RetryDelay:   time.Second,
+		Userhash:     quax.Userhash,
+		allowedHosts: quax.allowedHosts,
+	}
+}
+
+// Upload uploads data like Quax, retrying on network errors, 429 and 5xx replies.
+func (u *QuaxUploader) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	quax := &Quax{Client: u.Client, Userhash: u.Userhash, allowedHosts: u.allowedHosts, endpoint: u.endpoint}
+	if quax.Client == nil {
+		quax.Client = NewQuax().Client
+	}
+	for attempt := 0; ; attempt++ {
+		fileURL, err := quax.rawUploadContext(ctx, data, name)
+		if err == nil || attempt >= u.MaxRetries || !isRetryableUpload(err) {
+			return fileURL, err
+		}
+		select {
+		case <-time.After(u.RetryDelay):
+		case <-ctx.Done():
+			return "", fmt.Errorf("quax retry wait: %w", ctx.Err())
+		}
+	}
+}
+
+// Delete always fails with ErrNotSupported, see Quax.Delete().
+func (u *QuaxUploader) Delete(ctx context.Context, fileURL string) error {
+	return new(Quax).Delete(ctx, fileURL)
+}
+
+// RateLimitedQuax is an Uploader for Quax capping the total upload bandwidth, to stay
+// under Quax rate limits when many files are uploaded concurrently.
+// Uploads wait for their share of the bandwidth in the order they arrive.
+type RateLimitedQuax struct {
+	Inner        *Quax // NewQuax() if nil
+	BandwidthBps int64 // bytes per second
+
+	once    sync.Once
+	limiter *rate.Limiter
+}
+
+// Upload waits until len(data) bytes fit in the bandwidth, then uploads data through Inner.
+func (q *RateLimitedQuax) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	q.once.Do(func() {
+		burst := int(max(q.BandwidthBps, 1))
+		q.limiter = rate.NewLimiter(rate.Limit(q.BandwidthBps), burst)
+		q.limiter.AllowN(time.Now(), burst) // start empty, so the first second is capped too
+	})
+	if q.BandwidthBps > 0 {
+		// WaitN cannot wait for more than the burst at once.
+		for left := len(data); left > 0; {
+			n := min(left, q.limiter.Burst())
+			if err := q.limiter.WaitN(ctx, n); err != nil {
+				return "", fmt.Errorf("quax bandwidth wait: %w", err)
+			}
+			left -= n
+		}
+	}
+
+	quax := q.Inner
+	if quax == nil {
+		quax = NewQuax()
+	}
+	return quax.rawUploadContext(ctx, data, name)
+}
+
+// isRetryableUpload is isRetryable for uploads, where any 5xx reply is worth retrying.
+func isRetryableUpload(err error) bool {
+	var herr *HTTPStatusError
+	if errors.As(err, &herr) && herr.StatusCode >= 500 {
+		return true
+	}
+	return isRetryable(err)
+}
+
+func (quax *Quax) rawUpload(b []byte, name string) (string, error) {
+	return quax.rawUploadContext(context.Background(), b, name)
+}
+
+func (quax *Quax) rawUploadContext(ctx context.Context, b []byte, name string) (string, error) {
+	return quax.sizedUpload(ctx, bytes.NewReader(b), int64(len(b)), name)
 }
 
 func (quax *Quax) fileUpload(path string) (string, error) {
@@ -126,53 +407,133 @@ func (quax *Quax) fileUpload(path string) (string, error) {
 	}
 	defer file.Close()
 
-	if size := FileSize(path); size > 209715200 {
-		return "", fmt.Errorf("file too large, size: %d MB", size/1024/1024)
+	return quax.sizedUpload(context.Background(), file, FileSize(path), file.Name())
+}
+
+// sizedUpload uploads size bytes from r. The multipart framing is built upfront,
+// so the request has a Content-Length and needs no pipe.
+func (quax *Quax) sizedUpload(ctx context.Context, r io.Reader, size int64, name string) (string, error) {
+	if err := checkUploadSize(size); err != nil {
+		return "", err
 	}
 
-	r, w := io.Pipe()
-	m := multipart.NewWriter(w)
+	var head bytes.Buffer
+	m := multipart.NewWriter(&head)
+	m.WriteField("reqtype", "fileupload")
+	m.WriteField("userhash", quax.Userhash)
+	if _, err := m.CreateFormFile("files[]", filepath.Base(name)); err != nil {
+		return "", err
+	}
+	prefix := head.Len()
+	if err := m.Close(); err != nil {
+		return "", err
+	}
+	framing := head.Bytes()
 
-	go func() {
-		defer w.Close()
-		defer m.Close()
+	body := io.MultiReader(
+		bytes.NewReader(framing[:prefix]),
+		io.LimitReader(newProgressReader(r, size, quax.progress), size),
+		bytes.NewReader(framing[prefix:]),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, quax.uploadEndpoint(), body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = int64(len(framing)) + size
+	req.Header.Add("Content-Type", m.FormDataContentType())
+	return quax.send(req)
+}
 
-		m.WriteField("reqtype", "fileupload")
-		m.WriteField("userhash", quax.Userhash)
-		part, err := m.CreateFormFile("files[]", filepath.Base(file.Name()))
-		if err != nil {
-			return
-		}
+// readerUpload streams r to Quax as a multipart upload without buffering it in memory.
+// Used when the size is not known upfront.
+func (quax *Quax) readerUpload(ctx context.Context, r io.Reader, name string) (string, error) {
+	pr, pw := io.Pipe()
+	m := multipart.NewWriter(pw)
 
-		if _, err = io.Copy(part, file); err != nil {
-			return
-		}
+	go func() {
+		err := func() error {
+			m.WriteField("reqtype", "fileupload")
+			m.WriteField("userhash", quax.Userhash)
+			part, err := m.CreateFormFile("files[]", filepath.Base(name))
+			if err != nil {
+				return err
+			}
+			n, err := io.Copy(part, io.LimitReader(newProgressReader(r, -1, quax.progress), MaxUploadSize+1))
+			if err != nil {
+				return err
+			}
+			if err := checkUploadSize(n); err != nil {
+				return err
+			}
+			return m.Close()
+		}()
+		pw.CloseWithError(err)
 	}()
 
-	req, _ := http.NewRequest(http.MethodPost, ENDPOINT, r)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, quax.uploadEndpoint(), pr)
+	if err != nil {
+		pr.Close()
+		return "", err
+	}
 	req.Header.Add("Content-Type", m.FormDataContentType())
+	defer pr.Close()
+	return quax.send(req)
+}
 
-	resp, err := quax.Client.Do(req)
+// checkUploadSize fails for files larger than MaxUploadSize before anything is sent.
+func checkUploadSize(size int64) error {
+	if size > MaxUploadSize {
+		return withKind(ErrUploadFailure, fmt.Errorf("quax file too large: %d MB, the limit is %d MB",
+			size/1024/1024, MaxUploadSize/1024/1024))
+	}
+	return nil
+}
+
+// send executes an upload request and returns the validated URL of the uploaded file.
+func (quax *Quax) send(req *http.Request) (string, error) {
+	qr, err := quax.post(req)
 	if err != nil {
 		return "", err
 	}
+	if !qr.Success || len(qr.Files) == 0 {
+		return "", withKind(ErrUploadFailure, fmt.Errorf("quax upload failed"))
+	}
+	if err := quax.ValidateUploadURL(qr.Files[0].URL); err != nil {
+		return "", err
+	}
+
+	return qr.Files[0].URL, nil
+}
+
+// post executes an upload request and decodes the response.
+func (quax *Quax) post(req *http.Request) (*QuaxResponse, error) {
+	resp, err := quax.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return nil, withKind(ErrUploadFailure, fmt.Errorf("quax upload resp: %w", err))
+	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var qr QuaxResponse
 	err = json.Unmarshal([]byte(body), &qr)
 	if err != nil {
-		return "", fmt.Errorf("quax upload response unmarshal: %w", err)
-	}
-	if !qr.Success || len(qr.Files) == 0 {
-		return "", fmt.Errorf("quax upload failed")
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("quax upload response unmarshal: %w", err))
 	}
+	return &qr, nil
+}
 
-	return qr.Files[0].URL, nil
+func (quax *Quax) uploadEndpoint() string {
+	if quax.endpoint == "" {
+		return ENDPOINT
+	}
+	return quax.endpoint
 }
 
 // FileSeze returns file attritubes of size about an inode, and
@@ -0,0 +1,333 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeQuax returns a Quax pointed at a local server that reports the uploaded
+// file's size in the returned URL.
+func fakeQuax(t *testing.T) *Quax {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, part, err := r.FormFile("files[]")
+		if err != nil {
+			w.Write([]byte(`{"success":false}`))
+			return
+		}
+		f, _ := part.Open()
+		defer f.Close()
+		n, _ := io.Copy(io.Discard, f)
+		fmt.Fprintf(w, `{"success":true,"files":[{"url":"https://qu.ax/%d/%s"}]}`, n, part.Filename)
+	}))
+	t.Cleanup(srv.Close)
+	q := NewQuax()
+	q.endpoint = srv.URL
+	return q
+}
+
+func Test_QuaxValidateUploadURL(t *testing.T) {
+	q := NewQuax()
+	if err := q.ValidateUploadURL("https://qu.ax/abc.png"); err != nil {
+		t.Fatalf("ValidateUploadURL() rejected default host: %v", err)
+	}
+	if err := q.ValidateUploadURL("https://evil.example/abc.png"); err == nil {
+		t.Fatalf("expected error for host outside allowlist")
+	}
+	if err := q.ValidateUploadURL("https://qu.ax.evil.example/abc.png"); err == nil {
+		t.Fatalf("expected error for lookalike host")
+	}
+
+	q.WithAllowedURLHosts([]string{"cdn.example"})
+	if err := q.ValidateUploadURL("https://cdn.example/abc.png"); err != nil {
+		t.Fatalf("ValidateUploadURL() rejected configured host: %v", err)
+	}
+	if err := q.ValidateUploadURL("https://qu.ax/abc.png"); err == nil {
+		t.Fatalf("expected default host to be replaced by configured allowlist")
+	}
+}
+
+func Test_QuaxBuilder(t *testing.T) {
+	q := NewQuax()
+	if q.Client == nil || q.Client.Timeout != 30*time.Second {
+		t.Fatalf("expected default client with 30s timeout")
+	}
+
+	client := &http.Client{}
+	if q.WithHTTPClient(client); q.Client != client {
+		t.Fatalf("expected WithHTTPClient to set the given client")
+	}
+	q.WithTimeout(5 * time.Second)
+	if q.Client.Timeout != 5*time.Second || client.Timeout != 0 {
+		t.Fatalf("expected WithTimeout to configure a copy of the given client")
+	}
+
+	if NewQuax(nil).Client == nil || NewQuax(QuaxWithClient(nil)).Client == nil {
+		t.Fatalf("expected NewQuax(nil) to fall back to the default client")
+	}
+	q = NewQuax(QuaxWithClient(client), QuaxWithTimeout(time.Minute), QuaxWithUserhash("abc"))
+	if q.Client.Timeout != time.Minute || client.Timeout != 0 || q.Userhash != "abc" {
+		t.Fatalf("expected options to configure the Quax, got %+v", q)
+	}
+	if NewQuax(QuaxWithClient(http.DefaultClient), QuaxWithTimeout(time.Minute)); http.DefaultClient.Timeout != 0 {
+		t.Fatalf("expected http.DefaultClient to be left unchanged")
+	}
+	if NewQuax().WithUserhash("def").Userhash != "def" {
+		t.Fatalf("expected WithUserhash to set the userhash")
+	}
+}
+
+// checkProgress fails unless calls grow monotonically up to total.
+func checkProgress(t *testing.T, calls [][2]int64, total int64) {
+	t.Helper()
+	if len(calls) < 2 {
+		t.Fatalf("expected several progress updates, got %v", calls)
+	}
+	for i, c := range calls {
+		if c[1] != total || (i > 0 && c[0] <= calls[i-1][0]) {
+			t.Fatalf("progress not monotonic towards %d at update %d: %v", total, i, calls[max(0, i-1):i+1])
+		}
+	}
+	if last := calls[len(calls)-1]; last[0] != total {
+		t.Fatalf("expected last update at %d bytes, got %d", total, last[0])
+	}
+}
+
+func Test_QuaxProgress(t *testing.T) {
+	data := make([]byte, 1<<20)
+	var calls [][2]int64
+	q := fakeQuax(t).WithProgressFunc(func(uploaded, total int64) {
+		calls = append(calls, [2]int64{uploaded, total})
+	})
+	url, err := q.rawUpload(data, "big.bin")
+	if err != nil {
+		t.Fatalf("rawUpload returned error: %v", err)
+	}
+	if url != fmt.Sprintf("https://qu.ax/%d/big.bin", len(data)) {
+		t.Fatalf("unexpected URL %q", url)
+	}
+	checkProgress(t, calls, int64(len(data)))
+
+	path := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	calls = nil
+	fd := NewFileData("").WithUploader(fakeQuax(t).AsUploader()).WithProgress(func(uploaded, total int64) {
+		calls = append(calls, [2]int64{uploaded, total})
+	})
+	if _, err := fd.FromFile(context.Background(), path); err != nil {
+		t.Fatalf("FromFile returned error: %v", err)
+	}
+	checkProgress(t, calls, int64(len(data)))
+
+	calls = nil
+	if _, err := fd.FromBytes(data); err != nil {
+		t.Fatalf("FromBytes returned error: %v", err)
+	}
+	checkProgress(t, calls, int64(len(data)))
+}
+
+func Test_QuaxUploadURL(t *testing.T) {
+	content := make([]byte, 5000)
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/img/cat.png":
+			w.Write(content)
+		case "/stream/cat.png":
+			// Flushing before the end drops the Content-Length.
+			w.Write(content[:100])
+			w.(http.Flusher).Flush()
+			w.Write(content[100:])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer remote.Close()
+	q := fakeQuax(t)
+
+	for _, p := range []string{"/img/cat.png", "/stream/cat.png"} {
+		url, err := q.UploadURL(context.Background(), remote.URL+p)
+		if err != nil {
+			t.Fatalf("UploadURL(%s) returned error: %v", p, err)
+		}
+		if url != "https://qu.ax/5000/cat.png" {
+			t.Fatalf("UploadURL(%s): expected the full file uploaded as cat.png, got %s", p, url)
+		}
+	}
+
+	if _, err := q.UploadURL(context.Background(), remote.URL+"/missing.png"); err == nil {
+		t.Fatalf("expected error for a missing remote file")
+	}
+}
+
+func Test_QuaxBatchUpload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() returned error: %v", err)
+			return
+		}
+		if r.FormValue("reqtype") != "fileupload" {
+			t.Errorf("unexpected reqtype %q", r.FormValue("reqtype"))
+		}
+		var files []string
+		for _, fh := range r.MultipartForm.File["files[]"] {
+			f, _ := fh.Open()
+			b, _ := io.ReadAll(f)
+			f.Close()
+			if string(b) == "bad" {
+				files = append(files, `{"url":""}`)
+				continue
+			}
+			files = append(files, fmt.Sprintf(`{"url":"https://qu.ax/%s/%s"}`, b, fh.Filename))
+		}
+		fmt.Fprintf(w, `{"success":true,"files":[%s]}`, strings.Join(files, ","))
+	}))
+	defer srv.Close()
+	q := NewQuax()
+	q.endpoint = srv.URL
+
+	urls, err := q.BatchUpload(context.Background(), []BatchFile{
+		{Data: []byte("a"), Name: "one.txt"},
+		{Data: []byte("b"), Name: "dir/two.txt"},
+		{Data: []byte("c"), Name: "three.txt"},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpload() returned error: %v", err)
+	}
+	want := []string{"https://qu.ax/a/one.txt", "https://qu.ax/b/two.txt", "https://qu.ax/c/three.txt"}
+	if !slices.Equal(urls, want) {
+		t.Fatalf("BatchUpload() returned %v, want %v", urls, want)
+	}
+
+	urls, err = q.BatchUpload(context.Background(), []BatchFile{
+		{Data: []byte("bad"), Name: "x"},
+		{Data: []byte("b"), Name: "y"},
+		{Data: []byte("bad"), Name: "z"},
+	})
+	var berr *BatchUploadError
+	if !errors.As(err, &berr) || !errors.Is(err, ErrUploadFailure) || !slices.Equal(berr.Failed, []int{0, 2}) {
+		t.Fatalf("expected BatchUploadError for files 0 and 2, got %v", err)
+	}
+	if !slices.Equal(urls, []string{"", "https://qu.ax/b/y", ""}) {
+		t.Fatalf("expected URL of the successful file, got %v", urls)
+	}
+}
+
+func Test_QuaxUploadSizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected upload of a file over the limit")
+	}))
+	defer srv.Close()
+	q := NewQuax()
+	q.endpoint = srv.URL
+
+	_, err := q.AsUploader().(readerUploader).uploadReader(context.Background(), strings.NewReader(""), MaxUploadSize+1, "big.bin")
+	if !errors.Is(err, ErrUploadFailure) || !strings.Contains(err.Error(), "the limit is 200 MB") {
+		t.Fatalf("expected the size limit error, got %v", err)
+	}
+}
+
+func Test_QuaxUploaderRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			http.Error(w, "busy", http.StatusInternalServerError)
+			return
+		}
+		if r.FormValue("userhash") != "abc" {
+			t.Errorf("expected userhash abc, got %q", r.FormValue("userhash"))
+		}
+		w.Write([]byte(`{"success":true,"files":[{"url":"https://qu.ax/x.png"}]}`))
+	}))
+	defer srv.Close()
+
+	u := NewQuaxUploader(QuaxWithUserhash("abc"))
+	u.endpoint = srv.URL
+	u.RetryDelay = time.Millisecond
+	var _ Uploader = u
+
+	url, err := u.Upload(context.Background(), []byte("data"), "x.png")
+	if err != nil || url != "https://qu.ax/x.png" {
+		t.Fatalf("Upload() returned %q, %v", url, err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+
+	calls.Store(0)
+	u.MaxRetries = 1
+	_, err = u.Upload(context.Background(), []byte("data"), "x.png")
+	var herr *HTTPStatusError
+	if !errors.Is(err, ErrUploadFailure) || !errors.As(err, &herr) || herr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected an ErrUploadFailure with status 500 once retries run out, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected 2 attempts, got %d", n)
+	}
+}
+
+func Test_RateLimitedQuax(t *testing.T) {
+	q := &RateLimitedQuax{Inner: fakeQuax(t), BandwidthBps: 1024}
+	var _ Uploader = q
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url, err := q.Upload(context.Background(), make([]byte, 512), fmt.Sprintf("%d.bin", i))
+			if err != nil || !strings.HasPrefix(url, "https://qu.ax/512/") {
+				t.Errorf("Upload() returned %q, %v", url, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected 1 KB at 1 KB/s to take at least 1s, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := q.Upload(ctx, make([]byte, 512), "x.bin"); err == nil {
+		t.Fatalf("expected an error for a cancelled context")
+	}
+}
+
+func Test_QuaxUserhash(t *testing.T) {
+	var hashes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hashes = append(hashes, r.FormValue("userhash"))
+		w.Write([]byte(`{"success":true,"files":[{"url":"https://qu.ax/x.png"}]}`))
+	}))
+	defer srv.Close()
+	local := func(q *Quax) { q.endpoint = srv.URL }
+
+	if _, err := NewFileData("x.png").FromBytes([]byte("png"), local, QuaxWithUserhash("abc")); err != nil {
+		t.Fatalf("FromBytes() returned error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "x.png")
+	os.WriteFile(path, []byte("png"), 0o644)
+	if _, err := NewFileData("").FromFile(context.Background(), path, local, QuaxWithUserhash("def")); err != nil {
+		t.Fatalf("FromFile() returned error: %v", err)
+	}
+	if _, err := NewQuax(local).WithUserhash("ghi").Upload([]byte("png"), "x.png"); err != nil {
+		t.Fatalf("Upload() returned error: %v", err)
+	}
+	if want := []string{"abc", "def", "ghi"}; !slices.Equal(hashes, want) {
+		t.Fatalf("expected userhashes %v in the multipart bodies, got %v", want, hashes)
+	}
+}
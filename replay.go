@@ -0,0 +1,220 @@
+package hfs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// replayExchange is one line of a fixture file.
+type replayExchange struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	RequestBody string `json:"request_body,omitempty"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	// Body holds the response body if it is valid UTF-8, BodyBase64 otherwise.
+	Body       string `json:"body,omitempty"`
+	BodyBase64 []byte `json:"body_base64,omitempty"`
+}
+
+// recordingTransport writes every exchange going through inner to a fixture file.
+type recordingTransport struct {
+	inner   http.RoundTripper
+	outFile string
+
+	mu      sync.Mutex
+	started bool
+}
+
+// NewRecordingTransport returns a transport that sends requests through inner (http.DefaultTransport if nil)
+// and writes each request/response pair to outFile as a line of JSON, for NewReplayTransport() to serve later.
+// outFile is truncated by the first request. Response bodies are read fully before being returned.
+// Use it with WithHTTPClient(&http.Client{Transport: ...}).
+func NewRecordingTransport(inner http.RoundTripper, outFile string) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return &recordingTransport{inner: inner, outFile: outFile}
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex := replayExchange{Method: req.Method, URL: req.URL.String()}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("hfs record request body: %w", err)
+		}
+		b, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("hfs record request body: %w", err)
+		}
+		ex.RequestBody = string(b)
+	}
+
+	resp, err := rt.inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("hfs record response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	ex.Status = resp.StatusCode
+	ex.ContentType = resp.Header.Get("Content-Type")
+	if utf8.Valid(b) {
+		ex.Body = string(b)
+	} else {
+		ex.BodyBase64 = b
+	}
+	if err := rt.write(ex); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (rt *recordingTransport) write(ex replayExchange) error {
+	line, err := json.Marshal(ex)
+	if err != nil {
+		return fmt.Errorf("hfs record marshal: %w", err)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !rt.started {
+		flag |= os.O_TRUNC
+		if err := os.MkdirAll(filepath.Dir(rt.outFile), 0o755); err != nil {
+			return fmt.Errorf("hfs record: %w", err)
+		}
+	}
+	f, err := os.OpenFile(rt.outFile, flag, 0o644)
+	if err != nil {
+		return fmt.Errorf("hfs record: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("hfs record: %w", err)
+	}
+	rt.started = true
+	return f.Close()
+}
+
+// replayTransport serves the exchanges of a fixture file in order.
+type replayTransport struct {
+	mu        sync.Mutex
+	exchanges []replayExchange
+	next      int
+}
+
+// NewReplayTransport returns a transport serving the exchanges recorded by NewRecordingTransport() in fixtureFile,
+// one per request and in order. A request whose method or URL path differs from the next exchange fails,
+// as does any request after the last one, with ErrNoMoreReplays.
+func NewReplayTransport(fixtureFile string) (http.RoundTripper, error) {
+	f, err := os.Open(fixtureFile)
+	if err != nil {
+		return nil, fmt.Errorf("hfs replay: %w", err)
+	}
+	defer f.Close()
+
+	rt := &replayTransport{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var ex replayExchange
+		if err := json.Unmarshal(line, &ex); err != nil {
+			return nil, withKind(ErrDecodeFailure, fmt.Errorf("hfs replay %s line %d: %w", fixtureFile, n, err))
+		}
+		rt.exchanges = append(rt.exchanges, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("hfs replay: %w", err)
+	}
+	return rt, nil
+}
+
+func (rt *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.next >= len(rt.exchanges) {
+		return nil, fmt.Errorf("hfs replay %s %s: %w", req.Method, req.URL, ErrNoMoreReplays)
+	}
+	ex := rt.exchanges[rt.next]
+	if !matchesExchange(req, ex) {
+		return nil, fmt.Errorf("hfs replay: request %d is %s %s, fixture has %s %s",
+			rt.next, req.Method, req.URL, ex.Method, ex.URL)
+	}
+	rt.next++
+
+	body := []byte(ex.Body)
+	if ex.BodyBase64 != nil {
+		body = ex.BodyBase64
+	}
+	header := http.Header{}
+	if ex.ContentType != "" {
+		header.Set("Content-Type", ex.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// matchesExchange compares methods and URL paths only, so a fixture keeps working
+// when the host or query differs between recording and replay.
+func matchesExchange(req *http.Request, ex replayExchange) bool {
+	uri, err := url.Parse(ex.URL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(req.Method, ex.Method) && uri.Path == req.URL.Path
+}
+
+// ReplayT is the part of *testing.T used by SetupReplay.
+type ReplayT interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// SetupReplay returns a client for WithHTTPClient() that replays fixtureFile.
+// Built with the "record" tag (go test -tags record), the client instead sends
+// requests to the live servers and records them into fixtureFile.
+func SetupReplay(t ReplayT, fixtureFile string) *http.Client {
+	t.Helper()
+	if recordFixtures {
+		return &http.Client{Transport: NewRecordingTransport(newHTTPClient().Transport, fixtureFile)}
+	}
+	rt, err := NewReplayTransport(fixtureFile)
+	if err != nil {
+		t.Fatalf("SetupReplay: %v", err)
+	}
+	return &http.Client{Transport: rt}
+}
@@ -0,0 +1,6 @@
+//go:build !record
+
+package hfs
+
+// recordFixtures makes SetupReplay record fixtures from live servers.
+const recordFixtures = false
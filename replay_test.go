@@ -0,0 +1,136 @@
+package hfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The fixtures of Test_ReplayFileDataFromURL and Test_ReplayFileDataFromBytes are hand-written
+// in the format of NewRecordingTransport(), following the Gradio call API, not recorded from
+// the live space: they check the client end to end but cannot catch changes of the protocol.
+// Replace them with recordings of the space with: go test -tags record -run Test_Replay
+
+func Test_ReplayFileDataFromURL(t *testing.T) {
+	client := SetupReplay(t, "testdata/replay/filedata_from_url.jsonl")
+	hfs := fileDataFromURL(t, client)
+	checkReplayExhausted(t, hfs)
+}
+
+func Test_ReplayFileDataFromBytes(t *testing.T) {
+	client := SetupReplay(t, "testdata/replay/filedata_from_bytes.jsonl")
+	hfs := fileDataFromBytes(t, client)
+	checkReplayExhausted(t, hfs)
+}
+
+// fileDataFromURL runs the integration test Test_FileDataFromURL with client for every request.
+func fileDataFromURL(t *testing.T, client *http.Client) *HFSpace[any, any] {
+	t.Helper()
+	hfs := NewHfs[any, any](test_name, WithHTTPClient(client), WithBearerToken(test_hf_token))
+	fdi, err := NewFileData("").FromUrl(test_input_url)
+	if err != nil {
+		t.Fatalf("FromUrl() returned error: %v", err)
+	}
+	checkFileDataOutput(t, hfs, client, fdi)
+	return hfs
+}
+
+// fileDataFromBytes runs the integration test Test_FileDataFromBytes with client for every request,
+// including the download of the input and its upload to Quax.
+func fileDataFromBytes(t *testing.T, client *http.Client) *HFSpace[any, any] {
+	t.Helper()
+	resp, err := client.Get(test_input_url)
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Get() returned status code %d, expected 200", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil || len(data) == 0 {
+		t.Fatalf("expected non-empty input data, got %d bytes, %v", len(data), err)
+	}
+
+	fdi, err := NewFileData("").FromBytes(data, QuaxWithClient(client))
+	if err != nil {
+		t.Fatalf("FromBytes() returned error: %v", err)
+	}
+	hfs := NewHfs[any, any](test_name, WithHTTPClient(client), WithBearerToken(test_hf_token))
+	checkFileDataOutput(t, hfs, client, fdi)
+	return hfs
+}
+
+// checkFileDataOutput calls the space with fdi and downloads the output image.
+func checkFileDataOutput(t *testing.T, hfs *HFSpace[any, any], client *http.Client, fdi *FileData) {
+	t.Helper()
+	res, err := hfs.Do(test_endpoint, fdi, test_prompt, 0, true, 2.5, 1 /*28*/)
+	if err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+	if len(res) == 0 {
+		t.Fatalf("expected at least one result from Do()")
+	}
+
+	fd, err := toFileData(res[0])
+	if err != nil {
+		t.Fatalf("toFileData() returned error: %v", err)
+	}
+	body, err := FileDataReader(&fd, client, nil)
+	if err != nil {
+		t.Fatalf("FileDataReader() returned error: %v", err)
+	}
+	out, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || len(out) == 0 {
+		t.Fatalf("expected non-empty output, got %d bytes, %v", len(out), err)
+	}
+}
+
+// checkReplayExhausted checks that a request past the end of the fixture fails.
+func checkReplayExhausted(t *testing.T, hfs *HFSpace[any, any]) {
+	t.Helper()
+	if recordFixtures {
+		return
+	}
+	if _, err := hfs.Do(test_endpoint, test_prompt); !errors.Is(err, ErrNoMoreReplays) {
+		t.Fatalf("expected ErrNoMoreReplays once the fixture is exhausted, got %v", err)
+	}
+}
+
+func Test_RecordThenReplay(t *testing.T) {
+	srv := fakeGradio(t, "event: complete\ndata: [\"ok\"]\n\n", nil)
+	fixture := filepath.Join(t.TempDir(), "fixture.jsonl")
+
+	rec := newTestHfs[string, string](srv).WithHTTPClient(&http.Client{Transport: NewRecordingTransport(nil, fixture)})
+	if _, err := rec.Do("/predict", "x"); err != nil {
+		t.Fatalf("Do() returned error: %v", err)
+	}
+	recorded, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("ReadFile() returned error: %v", err)
+	}
+	if n := bytes.Count(recorded, []byte("\n")); n != 2 {
+		t.Fatalf("expected 2 recorded exchanges, got %d:\n%s", n, recorded)
+	}
+
+	rt, err := NewReplayTransport(fixture)
+	if err != nil {
+		t.Fatalf("NewReplayTransport() returned error: %v", err)
+	}
+	replay := NewHfs[string, string]("elsewhere").WithHTTPClient(&http.Client{Transport: rt})
+	res, err := replay.Do("/predict", "x")
+	if err != nil || len(res) != 1 || res[0] != "ok" {
+		t.Fatalf("replayed Do() returned %v, %v", res, err)
+	}
+
+	rt, _ = NewReplayTransport(fixture)
+	replay.WithHTTPClient(&http.Client{Transport: rt})
+	if _, err := replay.Do("/other", "x"); err == nil {
+		t.Fatalf("expected an error for a request not matching the fixture")
+	}
+}
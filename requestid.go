@@ -0,0 +1,31 @@
+package hfs
+
+import (
+	"context"
+	"net/http"
+)
+
+// requestIDKey is the context key of the request ID set by WithRequestID.
+type requestIDKey struct{}
+
+// withRequestID returns ctx carrying a new request ID, if WithRequestID is set.
+func (c *config) withRequestID(ctx context.Context) (context.Context, string) {
+	if c.requestID == nil {
+		return ctx, ""
+	}
+	id := c.requestID()
+	return context.WithValue(ctx, requestIDKey{}, id), id
+}
+
+// requestIDFrom returns the request ID carried by ctx, if any.
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// setRequestIDHeader sets X-Request-ID on req if its context carries a request ID.
+func setRequestIDHeader(req *http.Request) {
+	if id := requestIDFrom(req.Context()); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+}
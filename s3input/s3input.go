@@ -0,0 +1,64 @@
+// Package s3input creates hfs.FileData inputs from S3 objects.
+package s3input
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ucukertz/hfs"
+)
+
+// GetObjectAPI is the part of *s3.Client used by FromObject, so it can be replaced in tests.
+type GetObjectAPI interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+var _ GetObjectAPI = (*s3.Client)(nil)
+
+// FromS3 streams the object bucket/key in region to the default hfs Uploader and returns it as a FileData.
+// The object is never held in memory. Use FromObject for another Uploader or a configured client.
+func FromS3(ctx context.Context, bucket, key, region string, cfg aws.Config) (*hfs.FileData, error) {
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if region != "" {
+			o.Region = region
+		}
+	})
+	return FromObject(ctx, hfs.NewFileData(""), client, bucket, key)
+}
+
+// FromObject streams the object bucket/key through the Uploader of fd, like fd.FromReader().
+// OrigName is set to the base name of key unless fd has one, and Size to the object's ContentLength.
+func FromObject(ctx context.Context, fd *hfs.FileData, client GetObjectAPI, bucket, key string) (*hfs.FileData, error) {
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3input get object: %w", err)
+	}
+	defer out.Body.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := io.Copy(pw, out.Body)
+		pw.CloseWithError(err)
+	}()
+	defer pr.Close()
+
+	name := path.Base(key)
+	if fd.OrigName == "" {
+		fd.OrigName = name
+	}
+	fd, err = fd.FromReader(ctx, pr, name)
+	if err != nil {
+		return nil, fmt.Errorf("s3input %s/%s: %w", bucket, key, err)
+	}
+	if out.ContentLength != nil {
+		fd.Size = *out.ContentLength
+	}
+	return fd, nil
+}
@@ -0,0 +1,59 @@
+package s3input
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ucukertz/hfs"
+)
+
+type mockGetObject func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+
+func (m mockGetObject) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+type memoryUploader struct {
+	files map[string]string
+}
+
+func (u *memoryUploader) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	u.files[name] = string(data)
+	return "https://files.example/" + name, nil
+}
+
+func Test_FromObject(t *testing.T) {
+	client := mockGetObject(func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		if aws.ToString(params.Bucket) != "bucket" || aws.ToString(params.Key) != "inputs/cat.png" {
+			t.Errorf("unexpected GetObject of %s/%s", aws.ToString(params.Bucket), aws.ToString(params.Key))
+		}
+		return &s3.GetObjectOutput{
+			Body:          io.NopCloser(strings.NewReader("\x89PNG\r\n\x1a\n")),
+			ContentLength: aws.Int64(8),
+		}, nil
+	})
+	u := &memoryUploader{files: map[string]string{}}
+
+	fd, err := FromObject(context.Background(), hfs.NewFileData("").WithUploader(u), client, "bucket", "inputs/cat.png")
+	if err != nil {
+		t.Fatalf("FromObject returned error: %v", err)
+	}
+	if fd.URL != "https://files.example/cat.png" || fd.OrigName != "cat.png" || fd.Size != 8 {
+		t.Fatalf("unexpected FileData %+v", fd)
+	}
+	if u.files["cat.png"] != "\x89PNG\r\n\x1a\n" {
+		t.Fatalf("expected the object to be uploaded, got %q", u.files["cat.png"])
+	}
+
+	failing := mockGetObject(func(context.Context, *s3.GetObjectInput, ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		return nil, errors.New("NoSuchKey")
+	})
+	if _, err := FromObject(context.Background(), hfs.NewFileData("").WithUploader(u), failing, "bucket", "missing"); err == nil {
+		t.Fatalf("expected an error for a missing object")
+	}
+}
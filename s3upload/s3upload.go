@@ -0,0 +1,89 @@
+// Package s3upload provides an hfs.Uploader that stores files in an S3 bucket.
+package s3upload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ucukertz/hfs"
+)
+
+var _ hfs.Uploader = (*S3Uploader)(nil)
+
+// S3Uploader uploads files with PutObject.
+// The bucket must allow the space to GET the returned URL, either publicly or through PresignTTL.
+type S3Uploader struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string // prepended to every object key, e.g. "hfs/"
+
+	// PresignTTL, if set, makes Upload return a pre-signed GET URL valid for that long
+	// instead of the plain object URL.
+	PresignTTL time.Duration
+}
+
+// New creates an S3Uploader for bucket.
+func New(client *s3.Client, bucket string) *S3Uploader {
+	return &S3Uploader{Client: client, Bucket: bucket}
+}
+
+// Upload stores data under Prefix + name and returns its URL.
+func (u *S3Uploader) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	key := u.Prefix + path.Base(name)
+	_, err := u.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(u.Bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return "", fmt.Errorf("s3upload put object: %w", err)
+	}
+
+	if u.PresignTTL > 0 {
+		req, err := s3.NewPresignClient(u.Client).PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(u.Bucket),
+			Key:    aws.String(key),
+		}, s3.WithPresignExpires(u.PresignTTL))
+		if err != nil {
+			return "", fmt.Errorf("s3upload presign: %w", err)
+		}
+		return req.URL, nil
+	}
+	return u.objectURL(key), nil
+}
+
+// objectURL returns the plain URL of key, honouring a custom endpoint and path-style addressing.
+func (u *S3Uploader) objectURL(key string) string {
+	opts := u.Client.Options()
+	escaped := escapeKey(key)
+	if opts.BaseEndpoint != nil {
+		base := strings.TrimSuffix(*opts.BaseEndpoint, "/")
+		if opts.UsePathStyle {
+			return base + "/" + u.Bucket + "/" + escaped
+		}
+		if ep, err := url.Parse(base); err == nil {
+			ep.Host = u.Bucket + "." + ep.Host
+			return ep.String() + "/" + escaped
+		}
+	}
+	if opts.UsePathStyle {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", opts.Region, u.Bucket, escaped)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", u.Bucket, opts.Region, escaped)
+}
+
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,59 @@
+package s3upload
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ucukertz/hfs"
+)
+
+func Test_S3Uploader(t *testing.T) {
+	var gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.URL.Path, string(b)
+	}))
+	defer srv.Close()
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "id", SecretAccessKey: "secret"}, nil
+		}),
+	})
+	u := New(client, "bucket")
+	u.Prefix = "inputs/"
+
+	fd, err := hfs.NewFileData("my image.png").WithUploader(u).FromBytes([]byte("png"))
+	if err != nil {
+		t.Fatalf("FromBytes returned error: %v", err)
+	}
+	if gotPath != "/bucket/inputs/my image.png" || gotBody != "png" {
+		t.Fatalf("unexpected PutObject path %q or body %q", gotPath, gotBody)
+	}
+	if want := srv.URL + "/bucket/inputs/my%20image.png"; fd.URL != want {
+		t.Fatalf("expected URL %q, got %q", want, fd.URL)
+	}
+
+	u.PresignTTL = time.Minute
+	url, err := u.Upload(context.Background(), []byte("png"), "a.png")
+	if err != nil {
+		t.Fatalf("Upload returned error: %v", err)
+	}
+	if !strings.Contains(url, "X-Amz-Expires=60") {
+		t.Fatalf("expected pre-signed URL, got %q", url)
+	}
+}
@@ -0,0 +1,159 @@
+package hfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaViolation is one field that does not match the output schema.
+type SchemaViolation struct {
+	Path    string // JSON path of the offending value, e.g. "$[0].url"
+	Message string
+}
+
+// SchemaValidationError is returned when a result does not match the schema set with WithOutputSchema().
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Path + ": " + v.Message
+	}
+	return "hfs schema validation: " + strings.Join(msgs, "; ")
+}
+
+func (e *SchemaValidationError) Is(target error) bool {
+	return target == ErrSchemaValidation
+}
+
+// Paths returns the JSON paths of all failing fields.
+func (e *SchemaValidationError) Paths() []string {
+	paths := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		paths[i] = v.Path
+	}
+	return paths
+}
+
+// jsonSchema is the supported subset of JSON Schema draft-07:
+// type, required, properties and items.
+type jsonSchema struct {
+	Type       schemaTypes            `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+}
+
+// schemaTypes accepts both "type": "string" and "type": ["string", "null"].
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return fmt.Errorf("schema type must be a string or an array of strings")
+	}
+	*t = many
+	return nil
+}
+
+func parseSchema(schema []byte) (*jsonSchema, error) {
+	var s jsonSchema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf("hfs output schema parse: %w", err)
+	}
+	return &s, nil
+}
+
+// validate checks raw JSON against the schema. Returns a *SchemaValidationError on mismatch.
+func (s *jsonSchema) validate(raw []byte) error {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return fmt.Errorf("hfs schema validation decode: %w", err)
+	}
+	var violations []SchemaViolation
+	s.check("$", v, &violations)
+	if len(violations) > 0 {
+		return &SchemaValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *jsonSchema) check(path string, v any, out *[]SchemaViolation) {
+	if s == nil {
+		return
+	}
+	if len(s.Type) > 0 && !s.Type.match(v) {
+		*out = append(*out, SchemaViolation{
+			Path:    path,
+			Message: fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), jsonTypeOf(v)),
+		})
+		return
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				*out = append(*out, SchemaViolation{Path: path + "." + name, Message: "required field missing"})
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if field, ok := val[name]; ok {
+				s.Properties[name].check(path+"."+name, field, out)
+			}
+		}
+	case []any:
+		for i, item := range val {
+			s.Items.check(fmt.Sprintf("%s[%d]", path, i), item, out)
+		}
+	}
+}
+
+func (t schemaTypes) match(v any) bool {
+	actual := jsonTypeOf(v)
+	for _, want := range t {
+		if want == actual {
+			return true
+		}
+		if want == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf names the JSON Schema type of a value decoded by encoding/json.
+func jsonTypeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if val == float64(int64(val)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,60 @@
+package hfs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var test_schema = []byte(`{
+	"type": "array",
+	"items": {
+		"type": "object",
+		"required": ["url", "size"],
+		"properties": {
+			"url": {"type": "string"},
+			"size": {"type": "integer"},
+			"mime_type": {"type": ["string", "null"]}
+		}
+	}
+}`)
+
+func Test_SchemaValidate(t *testing.T) {
+	s, err := parseSchema(test_schema)
+	if err != nil {
+		t.Fatalf("parseSchema() returned error: %v", err)
+	}
+
+	if err := s.validate([]byte(`[{"url":"https://x","size":3,"mime_type":null}]`)); err != nil {
+		t.Fatalf("validate() returned error for valid input: %v", err)
+	}
+
+	err = s.validate([]byte(`[{"url":1,"mime_type":"image/png"},"oops"]`))
+	var verr *SchemaValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *SchemaValidationError, got %v", err)
+	}
+	want := []string{"$[0].size", "$[0].url", "$[1]"}
+	if !reflect.DeepEqual(verr.Paths(), want) {
+		t.Fatalf("expected paths %v, got %v", want, verr.Paths())
+	}
+}
+
+func Test_OutputSchema(t *testing.T) {
+	srv := fakeGradio(t, "event: complete\ndata: [{\"url\":\"https://x\"}]\n\n", nil)
+
+	hfs := newTestHfs[any, any](srv).WithOutputSchema(test_schema)
+	_, err := hfs.Do(test_endpoint)
+	var verr *SchemaValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *SchemaValidationError, got %v", err)
+	}
+	if !reflect.DeepEqual(verr.Paths(), []string{"$[0].size"}) {
+		t.Fatalf("unexpected failing paths: %v", verr.Paths())
+	}
+
+	hfs.WithOutputSchema([]byte(`{"type": 5}`))
+	if _, err := hfs.Do(test_endpoint); err == nil {
+		t.Fatalf("expected error for invalid schema")
+	}
+}
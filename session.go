@@ -0,0 +1,71 @@
+package hfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// SessionState calls a stateful Gradio demo, passing state back and forth between calls
+// by the convention that the state is the first input and the last output of the endpoint.
+// S is the state type; I must be able to hold an S, e.g. `any`.
+type SessionState[I, O, S any] struct {
+	Space *HFSpace[I, O]
+
+	mu    sync.Mutex
+	state S
+}
+
+// NewSessionState creates a SessionState on space starting from initial.
+func NewSessionState[I, O, S any](space *HFSpace[I, O], initial S) *SessionState[I, O, S] {
+	return &SessionState[I, O, S]{Space: space, state: initial}
+}
+
+// Do calls endpoint with state prepended to params. It returns the outputs without the
+// trailing state, and the new state, which is also kept for State().
+func (s *SessionState[I, O, S]) Do(endpoint string, state S, params ...I) ([]O, S, error) {
+	in, ok := any(state).(I)
+	if !ok {
+		return nil, state, fmt.Errorf("hfs session state %T is not a valid input", state)
+	}
+	res, err := s.Space.Do(endpoint, append([]I{in}, params...)...)
+	if err != nil {
+		return nil, state, err
+	}
+	if len(res) == 0 {
+		return nil, state, fmt.Errorf("hfs session state missing from result: %w", ErrNoData)
+	}
+
+	next, err := toState[S](res[len(res)-1])
+	if err != nil {
+		return nil, state, err
+	}
+	s.mu.Lock()
+	s.state = next
+	s.mu.Unlock()
+	return res[:len(res)-1], next, nil
+}
+
+// State returns the state returned by the last successful Do(), or the initial state.
+func (s *SessionState[I, O, S]) State() S {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// toState converts an output to S, by type assertion if possible, otherwise through JSON,
+// e.g. for O = any or json.RawMessage.
+func toState[S, O any](out O) (S, error) {
+	if state, ok := any(out).(S); ok {
+		return state, nil
+	}
+	var state S
+	b, err := json.Marshal(out)
+	if err != nil {
+		return state, fmt.Errorf("hfs session state encode: %w", err)
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return state, withKind(ErrDecodeFailure, fmt.Errorf("hfs session state decode: %w", err))
+	}
+	return state, nil
+}
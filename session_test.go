@@ -0,0 +1,56 @@
+package hfs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func Test_SessionState(t *testing.T) {
+	// The fake chat appends each message to the history it receives and returns
+	// (reply, history), like a gr.State based Gradio chatbot.
+	var history []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body struct {
+				Data []json.RawMessage `json:"data"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			var msg string
+			json.Unmarshal(body.Data[0], &history)
+			json.Unmarshal(body.Data[1], &msg)
+			history = append(history, msg)
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		data, _ := json.Marshal([]any{"you said " + history[len(history)-1], history})
+		w.Write([]byte("event: complete\ndata: " + string(data) + "\n\n"))
+	}))
+	defer srv.Close()
+
+	session := NewSessionState[any, any](newTestHfs[any, any](srv), []string{})
+
+	out, state, err := session.Do("/chat", session.State(), "hi")
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if len(out) != 1 || out[0] != "you said hi" {
+		t.Fatalf("unexpected outputs %v", out)
+	}
+	if !reflect.DeepEqual(state, []string{"hi"}) {
+		t.Fatalf("expected state [hi], got %v", state)
+	}
+
+	out, state, err = session.Do("/chat", state, "again")
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if out[0] != "you said again" || !reflect.DeepEqual(state, []string{"hi", "again"}) {
+		t.Fatalf("expected the second turn to build on the first, got %v, %v", out, state)
+	}
+	if !reflect.DeepEqual(session.State(), state) {
+		t.Fatalf("expected State() to return the last state, got %v", session.State())
+	}
+}
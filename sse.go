@@ -0,0 +1,90 @@
+package hfs
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// maxSSEEventSize bounds a single event, so a stream without blank lines cannot grow the buffer forever.
+// Final results carrying inline files can be large, hence the generous limit.
+const maxSSEEventSize = 64 << 20
+
+// sseEvent is one event of a Gradio event stream.
+type sseEvent struct {
+	Type string // "generating", "complete", "error", "heartbeat", ...
+	Data string // data lines joined with "\n", empty if the event had none
+}
+
+// sseScanner reads a Gradio event stream one event at a time, so only the current event is buffered.
+type sseScanner struct {
+	scanner *bufio.Scanner
+	event   sseEvent
+}
+
+func newSSEScanner(r io.Reader) *sseScanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxSSEEventSize)
+	scanner.Split(splitSSE)
+	return &sseScanner{scanner: scanner}
+}
+
+// Scan advances to the next event. It returns false at the end of the stream or on error.
+func (s *sseScanner) Scan() bool {
+	for s.scanner.Scan() {
+		if ev, ok := parseSSEEvent(s.scanner.Text()); ok {
+			s.event = ev
+			return true
+		}
+	}
+	return false
+}
+
+// Event returns the event read by the last call to Scan.
+func (s *sseScanner) Event() sseEvent {
+	return s.event
+}
+
+// Err returns the first read error, nil at a clean end of the stream.
+func (s *sseScanner) Err() error {
+	return s.scanner.Err()
+}
+
+// splitSSE is a bufio.SplitFunc returning one blank-line delimited event per token.
+func splitSSE(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	lf := bytes.Index(data, []byte("\n\n"))
+	crlf := bytes.Index(data, []byte("\r\n\r\n"))
+	if crlf >= 0 && (lf < 0 || crlf < lf) {
+		return crlf + 4, data[:crlf], nil
+	}
+	if lf >= 0 {
+		return lf + 2, data[:lf], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// parseSSEEvent parses the lines of one event. ok is false for blocks with neither event nor data.
+func parseSSEEvent(block string) (ev sseEvent, ok bool) {
+	hasData := false
+	for len(block) > 0 {
+		var line string
+		line, block, _ = strings.Cut(block, "\n")
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			ev.Type = strings.TrimSpace(line[len("event:"):])
+			ok = true
+		case strings.HasPrefix(line, "data:"):
+			data := strings.TrimSpace(line[len("data:"):])
+			if hasData {
+				data = ev.Data + "\n" + data
+			}
+			ev.Data, hasData, ok = data, true, true
+		}
+	}
+	return ev, ok
+}
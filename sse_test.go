@@ -0,0 +1,81 @@
+package hfs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_SSEScanner(t *testing.T) {
+	stream := "event: generating\ndata: [1]\n\n" +
+		": comment\n\n" +
+		"event: heartbeat\r\ndata: null\r\n\r\n" +
+		"data: [2]\ndata: [3]\n\n" +
+		"event: complete\ndata: [4]"
+
+	var got []sseEvent
+	scanner := newSSEScanner(strings.NewReader(stream))
+	for scanner.Scan() {
+		got = append(got, scanner.Event())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	want := []sseEvent{
+		{Type: "generating", Data: "[1]"},
+		{Type: "heartbeat", Data: "null"},
+		{Data: "[2]\n[3]"},
+		{Type: "complete", Data: "[4]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+// syntheticSSE returns a ~1 MB event stream of progress updates ending in a "complete" event.
+func syntheticSSE() []byte {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 1<<20; i++ {
+		fmt.Fprintf(&buf, "event: generating\ndata: {\"msg\":\"progress\",\"progress_data\":[{\"index\":%d,\"length\":100000,\"unit\":\"steps\"}]}\n\n", i)
+	}
+	buf.WriteString("event: complete\ndata: [\"done\"]\n\n")
+	return buf.Bytes()
+}
+
+func Benchmark_ReadData(b *testing.B) {
+	body := syntheticSSE()
+	hfs := NewHfs[any, string]("test")
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	for b.Loop() {
+		if _, err := hfs.readData(context.Background(), io.NopCloser(bytes.NewReader(body)), "evt"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark_ReadDataReadAll is the baseline of reading the whole stream before parsing it.
+func Benchmark_ReadDataReadAll(b *testing.B) {
+	body := syntheticSSE()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	for b.Loop() {
+		all, err := io.ReadAll(bytes.NewReader(body))
+		if err != nil {
+			b.Fatal(err)
+		}
+		var data string
+		for line := range strings.Lines(string(all)) {
+			if strings.HasPrefix(line, "data:") {
+				data = strings.TrimSpace(line[len("data:"):])
+			}
+		}
+		if data == "" {
+			b.Fatal("no data")
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package hfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StreamEvent is one event of a Gradio event stream delivered by DoStream().
+type StreamEvent[O any] struct {
+	Data      []O    // decoded payload, nil for events without one
+	EventType string // "generating", "complete", "error", ...
+	Err       error  // set when the stream failed; it is the last event on the channel
+}
+
+// DoStream is like DoWithContext() but delivers every event with a payload as it arrives,
+// e.g. intermediate "generating" results. The request runs like in Do(), with its retries,
+// circuit breaker, rate limit, metrics and generating timeout, so a retried request may
+// repeat the events of the failed attempt. The synchronous APIs of WithEndpointBase(), and
+// requests deduplicated by WithDeduplicationWindow(), only deliver the "complete" event.
+// Progress messages are not delivered as events but passed to the callback of
+// WithProgressCallback(), as in Do().
+//
+// Invalid params are returned as the error; failures of the request are delivered as an
+// event carrying Err. The channel is closed after the "complete" event, after an event
+// carrying Err, or when ctx is done.
+func (h *HFSpace[I, O]) DoStream(ctx context.Context, endpoint string, params ...I) (<-chan StreamEvent[O], error) {
+	if err := validateParams(params); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	events := make(chan StreamEvent[O])
+	send := func(ev StreamEvent[O]) bool {
+		select {
+		case events <- ev:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// observe runs on the goroutine of the request, which stops once failed is set.
+	failed := false
+	observe := func(event, data string) {
+		if failed || data == "" || data == "null" || strings.HasPrefix(data, "{") {
+			return
+		}
+		var res []O
+		if err := json.Unmarshal([]byte(data), &res); err != nil {
+			failed = true
+			send(StreamEvent[O]{EventType: event, Err: withKind(ErrDecodeFailure, fmt.Errorf("hfs decode stream event: %w", err))})
+			cancel()
+			return
+		}
+		if h.propagateAuth {
+			for i := range res {
+				h.authorizeOutput(&res[i])
+			}
+		}
+		if !send(StreamEvent[O]{Data: res, EventType: event}) {
+			failed = true
+		}
+	}
+
+	go func() {
+		defer close(events)
+		defer cancel()
+
+		data, err := h.callData(context.WithValue(ctx, eventObserverKey{}, observe), endpoint, params)
+		if failed || ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			ev := StreamEvent[O]{Err: err}
+			if errors.Is(err, ErrEventError) {
+				ev.EventType = "error"
+			}
+			send(ev)
+			return
+		}
+		res, err := h.decodeResult(data)
+		send(StreamEvent[O]{Data: res, EventType: "complete", Err: err})
+	}()
+	return events, nil
+}
@@ -0,0 +1,180 @@
+package hfs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_DoStream(t *testing.T) {
+	sse := "event: heartbeat\ndata: null\n\n" +
+		"event: generating\ndata: [\"a\"]\n\n" +
+		"event: generating\ndata: [\"ab\"]\n\n" +
+		"event: complete\ndata: [\"abc\"]\n\n" +
+		"event: generating\ndata: [\"after complete\"]\n\n"
+	srv := fakeGradio(t, sse, nil)
+
+	events, err := newTestHfs[any, string](srv).DoStream(context.Background(), test_endpoint)
+	if err != nil {
+		t.Fatalf("DoStream() returned error: %v", err)
+	}
+	var got []string
+	for ev := range events {
+		if ev.Err != nil {
+			t.Fatalf("unexpected stream error: %v", ev.Err)
+		}
+		got = append(got, ev.EventType+":"+ev.Data[0])
+	}
+	want := []string{"generating:a", "generating:ab", "complete:abc"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func Test_DoStreamProgress(t *testing.T) {
+	sse := "event: generating\n" +
+		`data: {"msg": "progress", "progress_data": [{"value": 3, "length": 10, "desc": "Generating", "unit": "steps"}]}` + "\n\n" +
+		"event: generating\ndata: [\"a\"]\n\n" +
+		"event: complete\ndata: [\"ab\"]\n\n"
+	srv := fakeGradio(t, sse, nil)
+
+	var got []string
+	hfs := newTestHfs[any, string](srv).WithProgressCallback(func(p ProgressEvent) {
+		got = append(got, p.String())
+	})
+	events, err := hfs.DoStream(context.Background(), test_endpoint)
+	if err != nil {
+		t.Fatalf("DoStream() returned error: %v", err)
+	}
+	n := 0
+	for ev := range events {
+		if ev.Err != nil {
+			t.Fatalf("unexpected stream error: %v", ev.Err)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 data events, got %d", n)
+	}
+	if len(got) != 1 || got[0] != "Generating: 3/10 steps" {
+		t.Fatalf("expected the progress update to reach the callback, got %v", got)
+	}
+}
+
+func Test_DoStreamError(t *testing.T) {
+	srv := fakeGradio(t, "event: generating\ndata: [\"a\"]\n\nevent: error\ndata: null\n\n", nil)
+
+	events, err := newTestHfs[any, string](srv).DoStream(context.Background(), test_endpoint)
+	if err != nil {
+		t.Fatalf("DoStream() returned error: %v", err)
+	}
+	var last StreamEvent[string]
+	n := 0
+	for ev := range events {
+		last = ev
+		n++
+	}
+	if n != 2 || !errors.Is(last.Err, ErrEventError) {
+		t.Fatalf("expected 1 data event then ErrEventError, got %d events, last %+v", n, last)
+	}
+}
+
+func Test_DoStreamCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: generating\ndata: [\"a\"]\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := newTestHfs[any, string](srv).DoStream(ctx, test_endpoint)
+	if err != nil {
+		t.Fatalf("DoStream() returned error: %v", err)
+	}
+	if ev := <-events; ev.Err != nil || ev.Data[0] != "a" {
+		t.Fatalf("unexpected first event: %+v", ev)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			for range events {
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("channel not closed after cancellation")
+	}
+}
+
+func Test_DoStreamRetry(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if posts.Add(1) == 1 {
+				http.Error(w, "starting", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"event_id":"evt"}`))
+			return
+		}
+		w.Write([]byte("event: generating\ndata: [\"a\"]\n\nevent: complete\ndata: [\"ab\"]\n\n"))
+	}))
+	defer srv.Close()
+
+	events, err := newTestHfs[any, string](srv).WithRetry(2, time.Millisecond, 1).DoStream(context.Background(), test_endpoint)
+	if err != nil {
+		t.Fatalf("DoStream() returned error: %v", err)
+	}
+	var got []string
+	for ev := range events {
+		if ev.Err != nil {
+			t.Fatalf("unexpected stream error: %v", ev.Err)
+		}
+		got = append(got, ev.EventType+":"+ev.Data[0])
+	}
+	if strings.Join(got, ",") != "generating:a,complete:ab" || posts.Load() != 2 {
+		t.Fatalf("expected the 503 to be retried, got %v after %d POSTs", got, posts.Load())
+	}
+
+	var herr *HTTPStatusError
+	posts.Store(0)
+	events, _ = newTestHfs[any, string](srv).DoStream(context.Background(), test_endpoint)
+	if ev := <-events; !errors.As(ev.Err, &herr) || herr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the 503 as the last event without retries, got %+v", ev)
+	}
+}
+
+func Test_DoStreamSync(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":["ok"],"duration":0.1}`))
+	}))
+	defer srv.Close()
+
+	events, err := newTestHfs[any, string](srv).WithEndpointBase("/run").DoStream(context.Background(), "/predict")
+	if err != nil {
+		t.Fatalf("DoStream() returned error: %v", err)
+	}
+	var got []StreamEvent[string]
+	for ev := range events {
+		got = append(got, ev)
+	}
+	if len(got) != 1 || got[0].Err != nil || got[0].EventType != "complete" || got[0].Data[0] != "ok" {
+		t.Fatalf("expected the result as the only event, got %+v", got)
+	}
+}
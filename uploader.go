@@ -0,0 +1,124 @@
+package hfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Uploader stores file content somewhere publicly reachable and returns its URL.
+// The Gradio "curl" API only accepts files by URL, so FileData inputs go through an Uploader.
+type Uploader interface {
+	Upload(ctx context.Context, data []byte, name string) (string, error)
+}
+
+// Deleter is implemented by uploaders that can remove a file they stored, see FileData.Delete().
+type Deleter interface {
+	Delete(ctx context.Context, fileURL string) error
+}
+
+// readerUploader is implemented by uploaders that can stream content instead of buffering it.
+// size is -1 if unknown.
+type readerUploader interface {
+	uploadReader(ctx context.Context, r io.Reader, size int64, name string) (string, error)
+}
+
+var (
+	defaultUploaderMu sync.RWMutex
+	defaultUploader   Uploader
+)
+
+// SetDefaultUploader sets the Uploader used by FileData without one of its own.
+// Passing nil restores the default Quax uploader.
+func SetDefaultUploader(u Uploader) {
+	defaultUploaderMu.Lock()
+	defer defaultUploaderMu.Unlock()
+	defaultUploader = u
+}
+
+func getDefaultUploader() Uploader {
+	defaultUploaderMu.RLock()
+	defer defaultUploaderMu.RUnlock()
+	if defaultUploader == nil {
+		return NewQuax().AsUploader()
+	}
+	return defaultUploader
+}
+
+// AsUploader returns quax as an Uploader.
+// Quax.Upload predates the Uploader interface and has a different signature.
+func (quax *Quax) AsUploader() Uploader {
+	return quaxUploader{quax}
+}
+
+type quaxUploader struct {
+	quax *Quax
+}
+
+func (u quaxUploader) Upload(ctx context.Context, data []byte, name string) (string, error) {
+	return u.quax.sizedUpload(ctx, bytes.NewReader(data), int64(len(data)), name)
+}
+
+func (u quaxUploader) uploadReader(ctx context.Context, r io.Reader, size int64, name string) (string, error) {
+	if size >= 0 {
+		return u.quax.sizedUpload(ctx, r, size, name)
+	}
+	return u.quax.readerUpload(ctx, r, name)
+}
+
+func (u quaxUploader) Delete(ctx context.Context, fileURL string) error {
+	return u.quax.Delete(ctx, fileURL)
+}
+
+// uploadReader streams size bytes (-1 if unknown) of r through u if supported, otherwise reads it fully first.
+func uploadReader(ctx context.Context, u Uploader, r io.Reader, size int64, name string) (string, error) {
+	if ru, ok := u.(readerUploader); ok {
+		return ru.uploadReader(ctx, r, size, name)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return u.Upload(ctx, data, name)
+}
+
+// Delete removes the uploaded content of fd through the Uploader that stored it.
+// It returns an error matching ErrNotSupported if fd was not uploaded by FromBytes, FromBase64,
+// FromReader or FromFile, or if its Uploader cannot delete (see Deleter), as with Quax.
+func (fd *FileData) Delete(ctx context.Context) error {
+	if fd.uploadedBy == nil {
+		return fmt.Errorf("hfs filedata delete: not uploaded by this FileData: %w", ErrNotSupported)
+	}
+	d, ok := fd.uploadedBy.(Deleter)
+	if !ok {
+		return fmt.Errorf("hfs filedata delete: uploader %T cannot delete: %w", fd.uploadedBy, ErrNotSupported)
+	}
+	if err := d.Delete(ctx, fd.URL); err != nil {
+		return fmt.Errorf("hfs filedata delete: %w", err)
+	}
+	return nil
+}
+
+// DeferDelete is fd.Delete() for use with defer: failures are logged to slog.Default() and otherwise ignored.
+// Uploaders without deletion support only log at Debug level.
+//
+//	fd, err := hfs.NewFileData("in.png").WithUploader(u).FromFile(ctx, "in.png")
+//	...
+//	defer hfs.DeferDelete(ctx, fd)
+func DeferDelete(ctx context.Context, fd *FileData) {
+	if fd == nil {
+		return
+	}
+	err := fd.Delete(ctx)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotSupported):
+		slog.DebugContext(ctx, "hfs filedata delete skipped", "url", fd.URL, "error", err)
+	default:
+		slog.WarnContext(ctx, "hfs filedata delete failed", "url", fd.URL, "error", err)
+	}
+}